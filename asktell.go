@@ -0,0 +1,72 @@
+package autocode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type OptimizationAskRequest struct {
+	Port  int64 `json:"port"`
+	Count int   `json:"count"`
+}
+
+type OptimizationAskResponse struct {
+	Candidates []map[string]*OptimizationValue `json:"candidates"`
+}
+
+type OptimizationTellRequest struct {
+	Port           int64                            `json:"port"`
+	VariableValues map[string]any                   `json:"variable_values"`
+	Response       *OptimizationEvaluateRunResponse `json:"response"`
+}
+
+// Ask requests count candidates from the backend, letting the caller drive the loop instead of serving evaluate
+// callbacks. Each candidate is reported back with Tell.
+func (self *Optimization) Ask(ctx context.Context, count int) (output []map[string]*OptimizationValue, err error) {
+	if count <= 0 {
+		err = fmt.Errorf("ask count must be positive: %d", count)
+		return output, err
+	}
+	requestBody := &OptimizationAskRequest{
+		Port:  self.ClientPort,
+		Count: count,
+	}
+	responseBody := &OptimizationAskResponse{}
+	requestErr := self.doRequest(ctx, http.MethodPost, "/apis/optimizations/asks", requestBody, responseBody)
+	if requestErr != nil {
+		err = fmt.Errorf("failed to ask: %w", requestErr)
+		return output, err
+	}
+	for _, candidate := range responseBody.Candidates {
+		normalizeErr := self.normalizeCandidate(candidate)
+		if normalizeErr != nil {
+			err = fmt.Errorf("failed to ask: %w", normalizeErr)
+			return output, err
+		}
+	}
+	output = responseBody.Candidates
+	return output, err
+}
+
+func (self *Optimization) Tell(ctx context.Context, candidate map[string]*OptimizationValue, response *OptimizationEvaluateRunResponse) (err error) {
+	if response == nil {
+		err = fmt.Errorf("tell response is missing")
+		return err
+	}
+	variableValues := map[string]any{}
+	for variableId, value := range candidate {
+		variableValues[variableId] = value.Map()
+	}
+	requestBody := &OptimizationTellRequest{
+		Port:           self.ClientPort,
+		VariableValues: variableValues,
+		Response:       response,
+	}
+	requestErr := self.doRequest(ctx, http.MethodPost, "/apis/optimizations/tells", requestBody, nil)
+	if requestErr != nil {
+		err = fmt.Errorf("failed to tell: %w", requestErr)
+		return err
+	}
+	return err
+}
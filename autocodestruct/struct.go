@@ -0,0 +1,185 @@
+package autocodestruct
+
+import (
+	"fmt"
+	"github.com/muazhari/autocode-go"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const TAG_NAME = autocode.OPTIMIZE_TAG
+
+func parseFloat(field reflect.StructField, text string) (output float64, err error) {
+	output, parseErr := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if parseErr != nil {
+		err = fmt.Errorf("invalid number in tag of field %s: %s", field.Name, text)
+		return output, err
+	}
+	if reflect.Zero(field.Type).OverflowFloat(output) == true {
+		err = fmt.Errorf("number in tag of field %s overflows %s: %s", field.Name, field.Type, text)
+		return output, err
+	}
+	return output, err
+}
+
+func parseInt(field reflect.StructField, text string) (output int64, err error) {
+	output, parseErr := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	if parseErr != nil {
+		err = fmt.Errorf("invalid integer in tag of field %s: %s", field.Name, text)
+		return output, err
+	}
+	if reflect.Zero(field.Type).OverflowInt(output) == true {
+		err = fmt.Errorf("integer in tag of field %s overflows %s: %s", field.Name, field.Type, text)
+		return output, err
+	}
+	return output, err
+}
+
+func parseOption(field reflect.StructField, text string) (output any, err error) {
+	switch field.Type.Kind() {
+	case reflect.Bool:
+		option, parseErr := strconv.ParseBool(strings.TrimSpace(text))
+		if parseErr != nil {
+			err = fmt.Errorf("invalid boolean in tag of field %s: %s", field.Name, text)
+			return output, err
+		}
+		output = option
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		output, err = parseInt(field, text)
+	case reflect.Float32, reflect.Float64:
+		output, err = parseFloat(field, text)
+	default:
+		err = fmt.Errorf("unsupported choice field type %s: %s", field.Type, field.Name)
+	}
+	return output, err
+}
+
+func isKind(field reflect.StructField, kinds ...reflect.Kind) (output bool) {
+	for _, kind := range kinds {
+		if field.Type.Kind() == kind {
+			output = true
+			return output
+		}
+	}
+	return output
+}
+
+func parseVariable(field reflect.StructField, tag string) (output any, err error) {
+	segments := strings.Split(tag, ",")
+	variableType := strings.TrimSpace(segments[0])
+	arguments := segments[1:]
+	switch variableType {
+	case "binary":
+		if len(arguments) != 0 {
+			err = fmt.Errorf("binary tag of field %s takes no arguments: %s", field.Name, tag)
+			return output, err
+		}
+		if isKind(field, reflect.Bool) == false {
+			err = fmt.Errorf("binary field must be bool: %s", field.Name)
+			return output, err
+		}
+		output = autocode.NewOptimizationBinary(field.Name)
+	case "integer":
+		if len(arguments) != 2 {
+			err = fmt.Errorf("integer tag of field %s needs lower and upper bounds: %s", field.Name, tag)
+			return output, err
+		}
+		if isKind(field, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64) == false {
+			err = fmt.Errorf("integer field must be an integer: %s", field.Name)
+			return output, err
+		}
+		lowerBound, lowerErr := parseInt(field, arguments[0])
+		if lowerErr != nil {
+			err = lowerErr
+			return output, err
+		}
+		upperBound, upperErr := parseInt(field, arguments[1])
+		if upperErr != nil {
+			err = upperErr
+			return output, err
+		}
+		output = autocode.NewOptimizationInteger(field.Name, lowerBound, upperBound)
+	case "real":
+		if len(arguments) != 2 {
+			err = fmt.Errorf("real tag of field %s needs lower and upper bounds: %s", field.Name, tag)
+			return output, err
+		}
+		if isKind(field, reflect.Float32, reflect.Float64) == false {
+			err = fmt.Errorf("real field must be a float: %s", field.Name)
+			return output, err
+		}
+		lowerBound, lowerErr := parseFloat(field, arguments[0])
+		if lowerErr != nil {
+			err = lowerErr
+			return output, err
+		}
+		upperBound, upperErr := parseFloat(field, arguments[1])
+		if upperErr != nil {
+			err = upperErr
+			return output, err
+		}
+		output = autocode.NewOptimizationReal(field.Name, lowerBound, upperBound)
+	case "choice":
+		if len(arguments) == 0 {
+			err = fmt.Errorf("choice tag of field %s needs at least one option: %s", field.Name, tag)
+			return output, err
+		}
+		options := []any{}
+		for _, argument := range arguments {
+			option, optionErr := parseOption(field, argument)
+			if optionErr != nil {
+				err = optionErr
+				return output, err
+			}
+			options = append(options, option)
+		}
+		output = autocode.NewOptimizationChoice(field.Name, options)
+	default:
+		err = fmt.Errorf("unsupported variable type in tag of field %s: %s", field.Name, variableType)
+	}
+	return output, err
+}
+
+func structType(instance any) (output reflect.Type, err error) {
+	output = reflect.TypeOf(instance)
+	if output != nil && output.Kind() == reflect.Pointer {
+		output = output.Elem()
+	}
+	if output == nil || output.Kind() != reflect.Struct {
+		err = fmt.Errorf("expected a struct or pointer to struct: %T", instance)
+		return output, err
+	}
+	return output, err
+}
+
+func Variables(instance any) (output []any, err error) {
+	instanceType, typeErr := structType(instance)
+	if typeErr != nil {
+		err = typeErr
+		return output, err
+	}
+	for index := 0; index < instanceType.NumField(); index++ {
+		field := instanceType.Field(index)
+		tag, tagExists := field.Tag.Lookup(TAG_NAME)
+		if tagExists == false {
+			continue
+		}
+		if field.IsExported() == false {
+			err = fmt.Errorf("tagged field must be exported: %s", field.Name)
+			return output, err
+		}
+		variable, variableErr := parseVariable(field, tag)
+		if variableErr != nil {
+			err = variableErr
+			return output, err
+		}
+		output = append(output, variable)
+	}
+	return output, err
+}
+
+func Decode(optimization *autocode.Optimization, into any) (err error) {
+	err = optimization.Unmarshal(into)
+	return err
+}
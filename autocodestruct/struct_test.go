@@ -0,0 +1,32 @@
+package autocodestruct
+
+import "testing"
+
+func TestVariablesRejectsTagsOverflowingTheField(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance any
+		valid    bool
+	}{
+		{name: "int8 bounds", instance: struct {
+			Depth int8 `optimize:"integer,0,127"`
+		}{}, valid: true},
+		{name: "int8 upper bound", instance: struct {
+			Depth int8 `optimize:"integer,0,300"`
+		}{}, valid: false},
+		{name: "int16 choice", instance: struct {
+			Width int16 `optimize:"choice,8,70000"`
+		}{}, valid: false},
+		{name: "float32 bound", instance: struct {
+			Rate float32 `optimize:"real,0,1e300"`
+		}{}, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Variables(test.instance)
+			if (err == nil) != test.valid {
+				t.Errorf("Variables() error = %v, want valid %t", err, test.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package autocodetest
+
+import (
+	"github.com/muazhari/autocode-go"
+	"sync"
+)
+
+type MockCall struct {
+	Optimization   *autocode.Optimization
+	VariableValues map[string]*autocode.OptimizationValue
+}
+
+type MockApplication struct {
+	Response *autocode.OptimizationEvaluateRunResponse
+	Err      error
+	Calls    []*MockCall
+	mutex    sync.Mutex
+}
+
+func NewMockApplication(response *autocode.OptimizationEvaluateRunResponse) *MockApplication {
+	return &MockApplication{
+		Response: response,
+		Calls:    []*MockCall{},
+	}
+}
+
+func (self *MockApplication) Evaluate(ctx *autocode.Optimization) *autocode.OptimizationEvaluateRunResponse {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	variableValues := map[string]*autocode.OptimizationValue{}
+	for variableId, value := range ctx.VariableValues {
+		variableValues[variableId] = value
+	}
+	self.Calls = append(self.Calls, &MockCall{
+		Optimization:   ctx,
+		VariableValues: variableValues,
+	})
+	if self.Err != nil {
+		panic(self.Err)
+	}
+	return self.Response
+}
+
+func (self *MockApplication) CallCount() (output int) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	output = len(self.Calls)
+	return output
+}
+
+func (self *MockApplication) LastCall() (output *MockCall) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	if len(self.Calls) == 0 {
+		return output
+	}
+	output = self.Calls[len(self.Calls)-1]
+	return output
+}
@@ -0,0 +1,89 @@
+package autocode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// SetBaseline makes EvaluateRun report objectives as deltas from the objectives of the given candidate, so
+// negative values mean an improvement when minimizing. Every variable that is not pinned needs a value. The
+// baseline is evaluated once, on the first evaluation after it is set.
+func (self *Optimization) SetBaseline(values map[string]any) (err error) {
+	baseline := map[string]*OptimizationValue{}
+	for variableId, value := range values {
+		baselineValue, baselineValueErr := self.newVariableValue(variableId, value)
+		if baselineValueErr != nil {
+			err = fmt.Errorf("invalid baseline: %w", baselineValueErr)
+			return err
+		}
+		baseline[variableId] = baselineValue
+	}
+	for variableId := range self.Variables {
+		_, valueExists := baseline[variableId]
+		_, pinnedValueExists := self.PinnedValues[variableId]
+		if valueExists == false && pinnedValueExists == false {
+			err = fmt.Errorf("invalid baseline: missing value of %s", variableId)
+			return err
+		}
+	}
+	err = self.ValidateCandidate(baseline)
+	if err != nil {
+		err = fmt.Errorf("invalid baseline: %w", err)
+		return err
+	}
+	self.Baseline = baseline
+	self.baselineObjectives = nil
+	return err
+}
+
+func (self *Optimization) ClearBaseline() {
+	self.Baseline = nil
+	self.baselineObjectives = nil
+}
+
+func (self *Optimization) evaluateBaseline(ctx context.Context) (output []float64, err error) {
+	variableValues := self.VariableValues
+	executedVariableValues := self.ExecutedVariableValues
+	resolvers := self.resolvers
+	defer func() {
+		self.VariableValues = variableValues
+		self.ExecutedVariableValues = executedVariableValues
+		self.resolvers = resolvers
+	}()
+	self.VariableValues = self.Baseline
+	self.ExecutedVariableValues = map[string]any{}
+	self.prepareResolvers()
+	evaluation, evaluationErr := self.evaluateCandidate(ctx)
+	if evaluationErr != nil {
+		err = fmt.Errorf("failed to evaluate baseline: %w", evaluationErr)
+		return output, err
+	}
+	if evaluation.Infeasible == true {
+		err = fmt.Errorf("baseline is infeasible")
+		return output, err
+	}
+	output = evaluation.Objectives
+	return output, err
+}
+
+func (self *Optimization) applyBaseline(ctx context.Context, response *OptimizationEvaluateRunResponse) (err error) {
+	if self.Baseline == nil || response.Infeasible == true {
+		return err
+	}
+	if self.baselineObjectives == nil {
+		self.baselineObjectives, err = self.evaluateBaseline(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	if len(response.Objectives) != len(self.baselineObjectives) {
+		err = fmt.Errorf("evaluation returned %d objectives, baseline returned %d", len(response.Objectives), len(self.baselineObjectives))
+		return err
+	}
+	response.Objectives = slices.Clone(response.Objectives)
+	for index := range response.Objectives {
+		response.Objectives[index] -= self.baselineObjectives[index]
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package autocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+type OptimizationBestRequest struct {
+	Port int64 `json:"port"`
+}
+
+type OptimizationBestResponse struct {
+	VariableValues map[string]*OptimizationValue    `json:"variable_values"`
+	Response       *OptimizationEvaluateRunResponse `json:"response"`
+}
+
+// Best asks the backend for its recommended candidate and the evaluation it recorded for it. Every declared
+// variable that is not pinned must have a valid value; values of group sub-variables are passed through. The
+// response is denormalized when Normalization is set, so it is on the scale Evaluate returned.
+func (self *Optimization) Best(ctx context.Context) (candidate map[string]*OptimizationValue, response *OptimizationEvaluateRunResponse, err error) {
+	requestBody := &OptimizationBestRequest{
+		Port: self.ClientPort,
+	}
+	responseBody := &OptimizationBestResponse{}
+	requestErr := self.doRequest(ctx, http.MethodPost, "/apis/optimizations/bests", requestBody, responseBody)
+	if requestErr != nil {
+		err = fmt.Errorf("failed to get best: %w", requestErr)
+		return candidate, response, err
+	}
+	if responseBody.VariableValues == nil || responseBody.Response == nil {
+		err = fmt.Errorf("failed to get best: backend has no recommended candidate")
+		return candidate, response, err
+	}
+	normalizeErr := self.normalizeCandidate(responseBody.VariableValues)
+	if normalizeErr != nil {
+		err = fmt.Errorf("failed to get best: %w", normalizeErr)
+		return candidate, response, err
+	}
+	variableIds := []string{}
+	for variableId := range self.Variables {
+		variableIds = append(variableIds, variableId)
+	}
+	sort.Strings(variableIds)
+	errs := []error{}
+	for _, variableId := range variableIds {
+		_, isPinned := self.PinnedValues[variableId]
+		value, valueExists := responseBody.VariableValues[variableId]
+		if isPinned == true && valueExists == false {
+			continue
+		}
+		valueErr := validateValue(self.Variables[variableId], value)
+		if valueErr != nil {
+			errs = append(errs, &VariableError{
+				VariableId: variableId,
+				Err:        valueErr,
+			})
+		}
+	}
+	validateErr := errors.Join(errs...)
+	if validateErr != nil {
+		err = fmt.Errorf("failed to get best: %w", validateErr)
+		return candidate, response, err
+	}
+	candidate = responseBody.VariableValues
+	response = responseBody.Response
+	if self.Normalization != nil {
+		response = self.Normalization.Denormalize(response)
+	}
+	return candidate, response, err
+}
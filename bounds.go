@@ -0,0 +1,32 @@
+package autocode
+
+import (
+	"fmt"
+	"math"
+)
+
+// INCLUSIVE_BOUNDS_KEY is sent with every integer variable so the backend does not have to guess whether the
+// upper bound is part of the range. Integer bounds are always inclusive at both ends on the wire.
+const INCLUSIVE_BOUNDS_KEY = "inclusive_bounds"
+
+// NewOptimizationIntegerRange builds an integer variable from bounds that may each be exclusive, and stores them
+// as the equivalent inclusive bounds, e.g. (0, 10] becomes [1, 10]. It panics when no integer is left.
+func NewOptimizationIntegerRange(id string, lowerBound int64, upperBound int64, lowerInclusive bool, upperInclusive bool, variableOptions ...VariableOption) *OptimizationInteger {
+	mustValidateId(id)
+	if lowerInclusive == false {
+		if lowerBound == math.MaxInt64 {
+			panic(fmt.Errorf("integer range of %s is empty: exclusive lower bound %d", id, lowerBound))
+		}
+		lowerBound++
+	}
+	if upperInclusive == false {
+		if upperBound == math.MinInt64 {
+			panic(fmt.Errorf("integer range of %s is empty: exclusive upper bound %d", id, upperBound))
+		}
+		upperBound--
+	}
+	if lowerBound > upperBound {
+		panic(fmt.Errorf("integer range of %s is empty: [%d, %d]", id, lowerBound, upperBound))
+	}
+	return NewOptimizationInteger(id, lowerBound, upperBound, variableOptions...)
+}
@@ -0,0 +1,145 @@
+package autocode
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	RESPONSE_OBJECTIVE  = "objective"
+	RESPONSE_INEQUALITY = "inequality"
+	RESPONSE_EQUALITY   = "equality"
+)
+
+type responseEntries struct {
+	kind     string
+	declared []string
+	names    []string
+	values   map[string]float64
+}
+
+func (self *responseEntries) add(name string, value float64) (err error) {
+	_, exists := self.values[name]
+	if exists == true {
+		err = fmt.Errorf("duplicate %s: %s", self.kind, name)
+		return err
+	}
+	self.names = append(self.names, name)
+	self.values[name] = value
+	return err
+}
+
+// build returns the values in declared order when names were declared, otherwise in insertion order.
+func (self *responseEntries) build() (output []float64, err error) {
+	order := self.names
+	if self.declared != nil {
+		declaredNames := map[string]bool{}
+		for _, name := range self.declared {
+			declaredNames[name] = true
+		}
+		for _, name := range self.names {
+			if declaredNames[name] == false {
+				err = errors.Join(err, fmt.Errorf("undeclared %s: %s", self.kind, name))
+			}
+		}
+		for _, name := range self.declared {
+			_, exists := self.values[name]
+			if exists == false {
+				err = errors.Join(err, fmt.Errorf("missing %s: %s", self.kind, name))
+			}
+		}
+		if err != nil {
+			return output, err
+		}
+		order = self.declared
+	}
+	output = make([]float64, 0, len(order))
+	for _, name := range order {
+		output = append(output, self.values[name])
+	}
+	return output, err
+}
+
+// ResponseBuilder assembles an OptimizationEvaluateRunResponse from named entries. Errors from the Add methods
+// are collected and returned by Build, so calls can be chained.
+type ResponseBuilder struct {
+	objectives   *responseEntries
+	inequalities *responseEntries
+	equalities   *responseEntries
+	counts       *OptimizationCounts
+	metadata     map[string]any
+	err          error
+}
+
+func NewResponseBuilder() *ResponseBuilder {
+	return &ResponseBuilder{
+		objectives:   &responseEntries{kind: RESPONSE_OBJECTIVE, values: map[string]float64{}},
+		inequalities: &responseEntries{kind: RESPONSE_INEQUALITY, values: map[string]float64{}},
+		equalities:   &responseEntries{kind: RESPONSE_EQUALITY, values: map[string]float64{}},
+	}
+}
+
+// Declare fixes the names and order of each slice. Build then fails on missing or undeclared entries instead
+// of following the order of the Add calls.
+func (self *ResponseBuilder) Declare(objectives []string, inequalities []string, equalities []string) *ResponseBuilder {
+	self.objectives.declared = append([]string{}, objectives...)
+	self.inequalities.declared = append([]string{}, inequalities...)
+	self.equalities.declared = append([]string{}, equalities...)
+	return self
+}
+
+// WithCounts makes Build check the slice lengths against counts, typically the Optimization's declared Counts.
+func (self *ResponseBuilder) WithCounts(counts *OptimizationCounts) *ResponseBuilder {
+	self.counts = counts
+	return self
+}
+
+func (self *ResponseBuilder) AddObjective(name string, value float64) *ResponseBuilder {
+	self.err = errors.Join(self.err, self.objectives.add(name, value))
+	return self
+}
+
+func (self *ResponseBuilder) AddInequality(name string, value float64) *ResponseBuilder {
+	self.err = errors.Join(self.err, self.inequalities.add(name, value))
+	return self
+}
+
+func (self *ResponseBuilder) AddEquality(name string, value float64) *ResponseBuilder {
+	self.err = errors.Join(self.err, self.equalities.add(name, value))
+	return self
+}
+
+func (self *ResponseBuilder) AddMetadata(key string, value any) *ResponseBuilder {
+	if self.metadata == nil {
+		self.metadata = map[string]any{}
+	}
+	self.metadata[key] = value
+	return self
+}
+
+func (self *ResponseBuilder) Build() (output *OptimizationEvaluateRunResponse, err error) {
+	err = self.err
+	objectives, objectivesErr := self.objectives.build()
+	inequalities, inequalitiesErr := self.inequalities.build()
+	equalities, equalitiesErr := self.equalities.build()
+	err = errors.Join(err, objectivesErr, inequalitiesErr, equalitiesErr)
+	if err != nil {
+		err = fmt.Errorf("failed to build response: %w", err)
+		return output, err
+	}
+	output = &OptimizationEvaluateRunResponse{
+		Objectives:            objectives,
+		InequalityConstraints: inequalities,
+		EqualityConstraints:   equalities,
+		Metadata:              self.metadata,
+	}
+	if self.counts != nil {
+		err = self.counts.Check(output)
+		if err != nil {
+			output = nil
+			err = fmt.Errorf("failed to build response: %w", err)
+			return output, err
+		}
+	}
+	return output, err
+}
@@ -0,0 +1,137 @@
+package autocode
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const EVICTION_LRU = "lru"
+const EVICTION_FIFO = "fifo"
+
+// OptimizationCache memoizes sub-results across evaluations, unlike ExecutedVariableValues which is reset on
+// every EvaluatePrepare. Cached sub-results must be pure functions of the candidate variable values they are
+// keyed on, otherwise a stale result computed for another candidate may be returned.
+type OptimizationCache struct {
+	Capacity       int
+	EvictionPolicy string
+	entries        map[string]*list.Element
+	order          *list.List
+	mutex          sync.Mutex
+}
+
+type optimizationCacheEntry struct {
+	key   string
+	value any
+}
+
+func NewOptimizationCache(capacity int, evictionPolicy string) *OptimizationCache {
+	if capacity <= 0 {
+		panic(fmt.Errorf("cache capacity must be positive: %d", capacity))
+	}
+	if evictionPolicy != EVICTION_LRU && evictionPolicy != EVICTION_FIFO {
+		panic(fmt.Errorf("unsupported eviction policy: %s", evictionPolicy))
+	}
+	return &OptimizationCache{
+		Capacity:       capacity,
+		EvictionPolicy: evictionPolicy,
+		entries:        map[string]*list.Element{},
+		order:          list.New(),
+	}
+}
+
+func (self *OptimizationCache) Get(key string) (output any, exists bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	element, exists := self.entries[key]
+	if exists == false {
+		return output, exists
+	}
+	if self.EvictionPolicy == EVICTION_LRU {
+		self.order.MoveToFront(element)
+	}
+	output = element.Value.(*optimizationCacheEntry).value
+	return output, exists
+}
+
+func (self *OptimizationCache) Set(key string, value any) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	element, exists := self.entries[key]
+	if exists == true {
+		element.Value.(*optimizationCacheEntry).value = value
+		if self.EvictionPolicy == EVICTION_LRU {
+			self.order.MoveToFront(element)
+		}
+		return
+	}
+	self.entries[key] = self.order.PushFront(&optimizationCacheEntry{
+		key:   key,
+		value: value,
+	})
+	for self.order.Len() > self.Capacity {
+		oldest := self.order.Back()
+		self.order.Remove(oldest)
+		delete(self.entries, oldest.Value.(*optimizationCacheEntry).key)
+	}
+}
+
+func (self *OptimizationCache) Len() (output int) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	output = self.order.Len()
+	return output
+}
+
+func (self *Optimization) EnableCache(capacity int, evictionPolicy string) {
+	self.Cache = NewOptimizationCache(capacity, evictionPolicy)
+}
+
+func (self *Optimization) GetCandidateHash(variableIds ...string) (output string) {
+	if len(variableIds) == 0 {
+		for variableId := range self.VariableValues {
+			variableIds = append(variableIds, variableId)
+		}
+		for variableId := range self.PinnedValues {
+			_, isVariableValue := self.VariableValues[variableId]
+			if isVariableValue == false {
+				variableIds = append(variableIds, variableId)
+			}
+		}
+	}
+	sortedVariableIds := append([]string{}, variableIds...)
+	sort.Strings(sortedVariableIds)
+	hash := sha256.New()
+	for _, variableId := range sortedVariableIds {
+		value, valueExists := self.selectedValue(variableId)
+		if valueExists == false {
+			panic(fmt.Errorf("variable value not found: %s", variableId))
+		}
+		valueJson, jsonErr := json.Marshal([]any{variableId, value.Id, value.Type, value.Data})
+		if jsonErr != nil {
+			panic(jsonErr)
+		}
+		hash.Write(valueJson)
+	}
+	output = hex.EncodeToString(hash.Sum(nil))
+	return output
+}
+
+func (self *Optimization) Memoize(key string, variableIds []string, compute func() any) (output any) {
+	if self.Cache == nil {
+		output = compute()
+		return output
+	}
+	cacheKey := fmt.Sprintf("%s:%s", key, self.GetCandidateHash(variableIds...))
+	cachedValue, cachedValueExists := self.Cache.Get(cacheKey)
+	if cachedValueExists == true {
+		return cachedValue
+	}
+	output = compute()
+	self.Cache.Set(cacheKey, output)
+	return output
+}
@@ -0,0 +1,30 @@
+package autocode
+
+import "testing"
+
+func TestGetCandidateHashResolvesPins(t *testing.T) {
+	variables := []any{NewOptimizationInteger("size", 0, 10), NewOptimizationInteger("depth", 0, 10)}
+	optimization := NewOptimization(variables, nil, "localhost", 0, 0)
+	optimization.VariableValues = map[string]*OptimizationValue{
+		"size": {Type: VALUE_INTEGER, Data: int64(1)},
+	}
+	for _, pinnedDepth := range []int64{2, 3} {
+		err := optimization.Pin("depth", pinnedDepth)
+		if err != nil {
+			t.Fatalf("Pin() error = %v", err)
+		}
+		hashes := map[string]bool{}
+		hashes[optimization.GetCandidateHash()] = true
+		hashes[optimization.GetCandidateHash("size", "depth")] = true
+		if len(hashes) != 1 {
+			t.Fatalf("GetCandidateHash() differs between the default and explicit ids: %v", hashes)
+		}
+	}
+	optimization.Pin("depth", int64(2))
+	firstHash := optimization.GetCandidateHash()
+	optimization.Pin("depth", int64(3))
+	secondHash := optimization.GetCandidateHash()
+	if firstHash == secondHash {
+		t.Errorf("GetCandidateHash() = %s for both pins, want different hashes", firstHash)
+	}
+}
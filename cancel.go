@@ -0,0 +1,70 @@
+package autocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+type OptimizationCancelRequest struct {
+	Port int64 `json:"port"`
+}
+
+func (self *Optimization) Cancel(ctx context.Context) (err error) {
+	requestBody := &OptimizationCancelRequest{
+		Port: self.ClientPort,
+	}
+	requestErr := self.doRequest(ctx, http.MethodPost, "/apis/optimizations/cancels", requestBody, nil)
+	if requestErr != nil {
+		err = fmt.Errorf("failed to cancel: %w", requestErr)
+		return err
+	}
+
+	err = self.StopClientServer(ctx)
+	return err
+}
+
+type ShutdownCallback = func() error
+
+// OnShutdown registers a callback releasing resources held by evaluations. Callbacks run in reverse
+// registration order after the client server stops, and all of them run even if some fail.
+func (self *Optimization) OnShutdown(callback ShutdownCallback) {
+	self.ShutdownCallbacks = append(self.ShutdownCallbacks, callback)
+}
+
+// StopClientServer shuts the client server down and runs the shutdown callbacks. Only the first call after a
+// Prepare does so; later calls, such as the run timeout racing Cancel, return nil.
+func (self *Optimization) StopClientServer(ctx context.Context) (err error) {
+	self.clientServerMutex.Lock()
+	server := self.ClientServer
+	listener := self.ClientListener
+	if server == nil || self.clientServerStopped == true {
+		self.clientServerMutex.Unlock()
+		return err
+	}
+	self.clientServerStopped = true
+	self.clientServerMutex.Unlock()
+	errs := []error{}
+	shutdownErr := server.ShutdownWithContext(ctx)
+	if shutdownErr != nil {
+		errs = append(errs, shutdownErr)
+	}
+	// Shutdown only closes listeners the server has started accepting on, so close the listener too in case
+	// the stop raced Serve.
+	if listener != nil {
+		closeErr := listener.Close()
+		if closeErr != nil && errors.Is(closeErr, net.ErrClosed) == false {
+			errs = append(errs, closeErr)
+		}
+	}
+	for index := len(self.ShutdownCallbacks) - 1; index >= 0; index-- {
+		callbackErr := self.ShutdownCallbacks[index]()
+		if callbackErr != nil {
+			errs = append(errs, callbackErr)
+		}
+	}
+	err = errors.Join(errs...)
+	return err
+}
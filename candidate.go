@@ -0,0 +1,113 @@
+package autocode
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+func validateValue(variable any, value *OptimizationValue) (err error) {
+	if value == nil {
+		err = fmt.Errorf("value is missing")
+		return err
+	}
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("invalid %s data %v: %v", value.Type, value.Data, recovered)
+		}
+	}()
+	switch typedVariable := variable.(type) {
+	case *OptimizationBinary:
+		if value.Type != VALUE_BOOLEAN && value.Type != VALUE_INTEGER && value.Type != VALUE_FLOAT {
+			err = fmt.Errorf("binary value must be %s: %s", VALUE_BOOLEAN, value.Type)
+			return err
+		}
+		toBool(value.Data)
+	case *OptimizationInteger:
+		if value.Type != VALUE_INTEGER {
+			err = fmt.Errorf("integer value must be %s: %s", VALUE_INTEGER, value.Type)
+			return err
+		}
+		integerValue := toInt64(value.Data)
+		if integerValue < typedVariable.LowerBound() || integerValue > typedVariable.UpperBound() {
+			err = fmt.Errorf("value is out of bounds %v: %d", typedVariable.Bounds, integerValue)
+			return err
+		}
+		if len(typedVariable.Values) > 0 && slices.Contains(typedVariable.Values, integerValue) == false {
+			err = fmt.Errorf("value is not in set %v: %d", typedVariable.Values, integerValue)
+			return err
+		}
+	case *OptimizationReal:
+		if value.Type != VALUE_FLOAT {
+			err = fmt.Errorf("real value must be %s: %s", VALUE_FLOAT, value.Type)
+			return err
+		}
+		realValue := toFloat64(value.Data)
+		if realValue < typedVariable.LowerBound() || realValue > typedVariable.UpperBound() {
+			err = fmt.Errorf("value is out of bounds %v: %v", typedVariable.Bounds, realValue)
+			return err
+		}
+	case *OptimizationChoice:
+		_, optionExists := typedVariable.Options[value.Id]
+		if optionExists == false {
+			err = fmt.Errorf("value is not an option: %s", value.Id)
+			return err
+		}
+	default:
+		err = fmt.Errorf("unsupported variable type: %T", variable)
+	}
+	return err
+}
+
+func (self *Optimization) normalizeCandidate(candidate map[string]*OptimizationValue) (err error) {
+	variables := self.allVariables()
+	for variableId, value := range candidate {
+		if value == nil {
+			err = fmt.Errorf("missing value of %s", variableId)
+			return err
+		}
+		_, isBinary := variables[variableId].(*OptimizationBinary)
+		if isBinary == true {
+			err = value.NormalizeBinary()
+		} else {
+			err = value.NormalizeData()
+		}
+		if err != nil {
+			err = fmt.Errorf("invalid value of %s: %w", variableId, err)
+			return err
+		}
+	}
+	return err
+}
+
+// ValidateCandidate checks every given value against the type and domain of its variable and joins one
+// VariableError per invalid value, ordered by variable id. Variables without a value are not reported. Values of
+// group sub-variables are checked under their namespaced ids.
+func (self *Optimization) ValidateCandidate(values map[string]*OptimizationValue) (err error) {
+	variables := self.allVariables()
+	variableIds := []string{}
+	for variableId := range values {
+		variableIds = append(variableIds, variableId)
+	}
+	sort.Strings(variableIds)
+	errs := []error{}
+	for _, variableId := range variableIds {
+		variable, variableExists := variables[variableId]
+		var valueErr error
+		if variableExists == false {
+			valueErr = fmt.Errorf("variable not found")
+		} else {
+			valueErr = validateValue(variable, values[variableId])
+		}
+		if valueErr != nil {
+			errs = append(errs, &VariableError{
+				VariableId: variableId,
+				Err:        valueErr,
+			})
+		}
+	}
+	err = errors.Join(errs...)
+	return err
+}
@@ -0,0 +1,148 @@
+package autocode
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func (self *Optimization) GetSelectedIndex(variableId string) (output int, err error) {
+	value, valueExists := self.selectedValue(variableId)
+	if valueExists == false {
+		err = fmt.Errorf("variable value not found: %s", variableId)
+		return output, err
+	}
+	variable, _ := self.findVariable(variableId)
+	choice, isChoice := variable.(*OptimizationChoice)
+	if isChoice == false {
+		err = fmt.Errorf("variable is not a choice: %s", variableId)
+		return output, err
+	}
+	prefix := variableId + choice.OptionIdSeparator()
+	if strings.HasPrefix(value.Id, prefix) == false {
+		err = fmt.Errorf("option id does not belong to variable %s: %s", variableId, value.Id)
+		return output, err
+	}
+	index, parseErr := strconv.Atoi(strings.TrimPrefix(value.Id, prefix))
+	if parseErr != nil {
+		err = fmt.Errorf("option id has no index suffix: %s: %w", value.Id, parseErr)
+		return output, err
+	}
+	output = index
+	return output, err
+}
+
+// GetSelectedOptionId returns the id of the selected option, which also works for choices built with
+// NewOptimizationChoiceNamed where GetSelectedIndex has no index to return.
+func (self *Optimization) GetSelectedOptionId(variableId string) (output string, err error) {
+	value, valueExists := self.selectedValue(variableId)
+	if valueExists == false {
+		err = fmt.Errorf("variable value not found: %s", variableId)
+		return output, err
+	}
+	output = value.Id
+	return output, err
+}
+
+func (self *Optimization) GetSelectedFunctionName(variableId string) (output string, err error) {
+	value, valueExists := self.selectedValue(variableId)
+	if valueExists == false {
+		err = fmt.Errorf("variable value not found: %s", variableId)
+		return output, err
+	}
+	if value.Type != VALUE_FUNCTION {
+		err = fmt.Errorf("selected value of %s is not a function: %s", variableId, value.Type)
+		return output, err
+	}
+	choice, isChoice := self.Variables[variableId].(*OptimizationChoice)
+	if isChoice == false {
+		err = fmt.Errorf("variable is not a choice: %s", variableId)
+		return output, err
+	}
+	option, optionExists := choice.Options[value.Id]
+	if optionExists == false {
+		err = fmt.Errorf("option not found in %s: %s", variableId, value.Id)
+		return output, err
+	}
+	output = option.Data.(*OptimizationFunctionValue).GetName()
+	return output, err
+}
+
+func (self *OptimizationChoice) optionIndex(optionId string) (output int, ok bool) {
+	prefix := self.Id + self.OptionIdSeparator()
+	if strings.HasPrefix(optionId, prefix) == false {
+		return output, ok
+	}
+	index, parseErr := strconv.Atoi(strings.TrimPrefix(optionId, prefix))
+	if parseErr != nil {
+		return output, ok
+	}
+	output = index
+	ok = true
+	return output, ok
+}
+
+func (self *OptimizationChoice) SortedOptionIds() (output []string) {
+	for optionId := range self.Options {
+		output = append(output, optionId)
+	}
+	sort.Slice(output, func(i int, j int) bool {
+		leftIndex, leftOk := self.optionIndex(output[i])
+		rightIndex, rightOk := self.optionIndex(output[j])
+		if leftOk == true && rightOk == true {
+			return leftIndex < rightIndex
+		}
+		if leftOk != rightOk {
+			return leftOk
+		}
+		return output[i] < output[j]
+	})
+	return output
+}
+
+// NormalizedString prints the function without its name and comments into a fresh file set, so the layout no
+// longer depends on the original line positions and equivalent functions print the same.
+func (self *OptimizationFunctionValue) NormalizedString() (output string, err error) {
+	functionDeclaration, declarationErr := self.declaration()
+	if declarationErr != nil {
+		err = declarationErr
+		return output, err
+	}
+	anonymousDeclaration := *functionDeclaration
+	anonymousDeclaration.Doc = nil
+	anonymousDeclaration.Name = ast.NewIdent("_")
+	buffer := &bytes.Buffer{}
+	printErr := printer.Fprint(buffer, token.NewFileSet(), &anonymousDeclaration)
+	if printErr != nil {
+		err = printErr
+		return output, err
+	}
+	output = buffer.String()
+	return output, err
+}
+
+func (self *OptimizationChoice) Deduplicate() (output int) {
+	seenSources := map[string]bool{}
+	for _, optionId := range self.SortedOptionIds() {
+		option := self.Options[optionId]
+		if option.Type != VALUE_FUNCTION {
+			continue
+		}
+		source, sourceErr := option.Data.(*OptimizationFunctionValue).NormalizedString()
+		if sourceErr != nil {
+			continue
+		}
+		if seenSources[source] == true {
+			delete(self.Options, optionId)
+			output++
+			continue
+		}
+		seenSources[source] = true
+	}
+	return output
+}
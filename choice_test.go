@@ -0,0 +1,108 @@
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func mixedChoiceFunction(ctx *Optimization, arguments ...any) any {
+	return "called"
+}
+
+func TestMixedChoiceRoundTrip(t *testing.T) {
+	choice := NewOptimizationChoice("option", []any{int64(8), 0.5, true, []byte("raw"), mixedChoiceFunction})
+	optimization := NewOptimization([]any{choice}, nil, "localhost", 0, 0)
+	tests := []struct {
+		optionId string
+		wantType string
+		want     any
+	}{
+		{optionId: "option_0", wantType: VALUE_INTEGER, want: int64(8)},
+		{optionId: "option_1", wantType: VALUE_FLOAT, want: 0.5},
+		{optionId: "option_2", wantType: VALUE_BOOLEAN, want: true},
+		{optionId: "option_3", wantType: VALUE_BYTES, want: []byte("raw")},
+		{optionId: "option_4", wantType: VALUE_FUNCTION, want: "called"},
+	}
+	for _, test := range tests {
+		t.Run(test.optionId, func(t *testing.T) {
+			option := choice.Options[test.optionId]
+			if option.Type != test.wantType {
+				t.Fatalf("option type = %s, want %s", option.Type, test.wantType)
+			}
+			optionJson, marshalErr := json.Marshal(option.Map())
+			if marshalErr != nil {
+				t.Fatalf("Marshal() error = %v", marshalErr)
+			}
+			recorder := postEvaluatePrepare(optimization, `{"variable_values": {"option": `+string(optionJson)+`}}`)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("EvaluatePrepare(%s) status = %d, body = %q", optionJson, recorder.Code, recorder.Body.String())
+			}
+			value := optimization.GetValue("option")
+			if reflect.DeepEqual(value, test.want) == false {
+				t.Errorf("GetValue() = %#v, want %#v", value, test.want)
+			}
+		})
+	}
+}
+
+func TestGetSelectedIndexWithIdEndingInNumber(t *testing.T) {
+	options := []any{}
+	for index := 0; index < 12; index++ {
+		options = append(options, int64(index))
+	}
+	tests := []struct {
+		name      string
+		choice    *OptimizationChoice
+		separator string
+	}{
+		{name: "default separator", choice: NewOptimizationChoice("layer_3", options), separator: "_"},
+		{name: "custom separator", choice: NewOptimizationChoiceSeparated("layer_3", "-", options), separator: "-"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			optimization := NewOptimization([]any{test.choice}, nil, "localhost", 0, 0)
+			sortedOptionIds := test.choice.SortedOptionIds()
+			for index, optionId := range sortedOptionIds {
+				want := fmt.Sprintf("layer_3%s%d", test.separator, index)
+				if optionId != want {
+					t.Fatalf("SortedOptionIds()[%d] = %q, want %q", index, optionId, want)
+				}
+			}
+			for _, index := range []int{0, 3, 11} {
+				optionId := fmt.Sprintf("layer_3%s%d", test.separator, index)
+				optimization.VariableValues = map[string]*OptimizationValue{
+					"layer_3": test.choice.Options[optionId],
+				}
+				selectedIndex, err := optimization.GetSelectedIndex("layer_3")
+				if err != nil {
+					t.Fatalf("GetSelectedIndex() error = %v", err)
+				}
+				if selectedIndex != index {
+					t.Errorf("GetSelectedIndex() = %d, want %d", selectedIndex, index)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateOptionIdSeparator(t *testing.T) {
+	tests := []struct {
+		separator string
+		valid     bool
+	}{
+		{separator: "_", valid: true},
+		{separator: "--", valid: true},
+		{separator: "", valid: false},
+		{separator: "_3", valid: false},
+		{separator: "/", valid: false},
+	}
+	for _, test := range tests {
+		err := ValidateOptionIdSeparator(test.separator)
+		if (err == nil) != test.valid {
+			t.Errorf("ValidateOptionIdSeparator(%q) error = %v, want valid %t", test.separator, err, test.valid)
+		}
+	}
+}
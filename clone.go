@@ -0,0 +1,209 @@
+package autocode
+
+import (
+	"fmt"
+	"net/http"
+)
+
+func (self *OptimizationVariable) Clone() *OptimizationVariable {
+	var metadata map[string]string
+	if self.Metadata != nil {
+		metadata = map[string]string{}
+		for key, value := range self.Metadata {
+			metadata[key] = value
+		}
+	}
+	return &OptimizationVariable{
+		Id:          self.Id,
+		Type:        self.Type,
+		Description: self.Description,
+		Metadata:    metadata,
+	}
+}
+
+func (self *OptimizationBinary) Clone() *OptimizationBinary {
+	return &OptimizationBinary{
+		OptimizationVariable: self.OptimizationVariable.Clone(),
+	}
+}
+
+func (self *OptimizationInteger) Clone() *OptimizationInteger {
+	return &OptimizationInteger{
+		OptimizationVariable: self.OptimizationVariable.Clone(),
+		Bounds:               self.Bounds,
+		Values:               append([]int64(nil), self.Values...),
+	}
+}
+
+func (self *OptimizationReal) Clone() *OptimizationReal {
+	return &OptimizationReal{
+		OptimizationVariable: self.OptimizationVariable.Clone(),
+		Bounds:               self.Bounds,
+	}
+}
+
+func (self *OptimizationValue) Clone() *OptimizationValue {
+	data := self.Data
+	switch typedData := self.Data.(type) {
+	case *OptimizationFunctionValue:
+		data = &OptimizationFunctionValue{
+			Function:               typedData.Function,
+			ContextFunction:        typedData.ContextFunction,
+			Timeout:                typedData.Timeout,
+			ErrorPotentiality:      typedData.ErrorPotentiality,
+			Understandability:      typedData.Understandability,
+			Complexity:             typedData.Complexity,
+			OverallMaintainability: typedData.OverallMaintainability,
+			Modularity:             typedData.Modularity,
+			Readability:            typedData.Readability,
+			name:                   typedData.name,
+			source:                 typedData.source,
+			sourceOwner:            typedData.sourceOwner,
+		}
+	case *OptimizationChoice:
+		data = typedData.Clone()
+	case *OptimizationGroup:
+		data = typedData.Clone()
+	case []byte:
+		data = append([]byte{}, typedData...)
+	}
+	return &OptimizationValue{
+		Id:   self.Id,
+		Type: self.Type,
+		Data: data,
+	}
+}
+
+func (self *OptimizationChoice) Clone() *OptimizationChoice {
+	options := map[string]*OptimizationValue{}
+	for optionId, option := range self.Options {
+		options[optionId] = option.Clone()
+	}
+	return &OptimizationChoice{
+		OptimizationVariable: self.OptimizationVariable.Clone(),
+		Options:              options,
+		optionIdSeparator:    self.optionIdSeparator,
+	}
+}
+
+func cloneVariable(variable any) (output any) {
+	switch typedVariable := variable.(type) {
+	case *OptimizationBinary:
+		output = typedVariable.Clone()
+	case *OptimizationInteger:
+		output = typedVariable.Clone()
+	case *OptimizationReal:
+		output = typedVariable.Clone()
+	case *OptimizationChoice:
+		output = typedVariable.Clone()
+	default:
+		panic(fmt.Errorf("unsupported variable type: %T", variable))
+	}
+	return output
+}
+
+// Clone deep-copies the variables and configuration but resets the per-run state. The Application, Evaluator,
+// HttpClient, Environment and registered callbacks are shared by reference unless replaced on the clone.
+func (self *Optimization) Clone() *Optimization {
+	variables := map[string]any{}
+	for variableId, variable := range self.Variables {
+		variables[variableId] = cloneVariable(variable)
+	}
+	exclusiveGroups := [][]string{}
+	for _, group := range self.ExclusiveGroups {
+		exclusiveGroups = append(exclusiveGroups, append([]string{}, group...))
+	}
+	var pinnedValues map[string]*OptimizationValue
+	if self.PinnedValues != nil {
+		pinnedValues = map[string]*OptimizationValue{}
+		for variableId, pinnedValue := range self.PinnedValues {
+			pinnedValues[variableId] = pinnedValue.Clone()
+		}
+	}
+	var cache *OptimizationCache
+	if self.Cache != nil {
+		cache = NewOptimizationCache(self.Cache.Capacity, self.Cache.EvictionPolicy)
+	}
+	var listenerOptions *ListenerOptions
+	if self.ListenerOptions != nil {
+		clonedListenerOptions := *self.ListenerOptions
+		listenerOptions = &clonedListenerOptions
+	}
+	var conditions map[string]*OptimizationCondition
+	if self.Conditions != nil {
+		conditions = map[string]*OptimizationCondition{}
+		for variableId, condition := range self.Conditions {
+			conditions[variableId] = condition
+		}
+	}
+	var history *evaluationHistory
+	if self.history != nil {
+		history = &evaluationHistory{}
+	}
+	var requestHeaders http.Header
+	if self.RequestHeaders != nil {
+		requestHeaders = self.RequestHeaders.Clone()
+	}
+	output := &Optimization{
+		Variables:              variables,
+		Application:            self.Application,
+		ServerHost:             self.ServerHost,
+		ServerPort:             self.ServerPort,
+		ServerUrl:              self.ServerUrl,
+		ClientPort:             self.ClientPort,
+		ProgressCallbacks:      append([]ProgressCallback{}, self.ProgressCallbacks...),
+		Cache:                  cache,
+		MaxRequestBodySize:     self.MaxRequestBodySize,
+		Normalization:          self.Normalization,
+		HttpClient:             self.HttpClient,
+		ExclusiveGroups:        exclusiveGroups,
+		Evaluator:              self.Evaluator,
+		PinnedValues:           pinnedValues,
+		RequestTimeout:         self.RequestTimeout,
+		RequestHeaders:         requestHeaders,
+		RetryCount:             self.RetryCount,
+		RetryBackoff:           self.RetryBackoff,
+		RetryNonIdempotent:     self.RetryNonIdempotent,
+		ParallelObjectives:     append([]ParallelObjective{}, self.ParallelObjectives...),
+		EvaluationTimeout:      self.EvaluationTimeout,
+		Penalty:                self.Penalty,
+		Language:               self.Language,
+		SourceRoot:             self.SourceRoot,
+		SourceFileSystem:       self.SourceFileSystem,
+		ListenerOptions:        listenerOptions,
+		InstrumentFunctions:    self.InstrumentFunctions,
+		Codec:                  self.Codec,
+		Codecs:                 append([]Codec{}, self.Codecs...),
+		Logger:                 self.Logger,
+		ChoiceOptionsSoftLimit: self.ChoiceOptionsSoftLimit,
+		ChoiceOptionsHardLimit: self.ChoiceOptionsHardLimit,
+		EqualityTolerances:     append([]float64(nil), self.EqualityTolerances...),
+		ServeRetryCount:        self.ServeRetryCount,
+		ServeRetryBackoff:      self.ServeRetryBackoff,
+		Counts:                 self.Counts,
+		FunctionConcurrency:    self.FunctionConcurrency,
+		functionSlots:          self.slots(),
+		Conditions:             conditions,
+		history:                history,
+		Noisy:                  self.Noisy,
+		NoiseLevel:             self.NoiseLevel,
+		AllowedRemotes:         append([]string(nil), self.AllowedRemotes...),
+		RunTimeout:             self.RunTimeout,
+		RunDeadline:            self.RunDeadline,
+		ShutdownCallbacks:      append([]ShutdownCallback{}, self.ShutdownCallbacks...),
+		Baseline:               self.Baseline,
+		baselineObjectives:     self.baselineObjectives,
+		MetricsProvider:        self.MetricsProvider,
+		Repeats:                self.Repeats,
+		ConstraintConditions:   append([]*OptimizationConstraintCondition(nil), self.ConstraintConditions...),
+		Environment:            self.Environment,
+		UnusedWarningAfter:     self.UnusedWarningAfter,
+		MaskedObjectives:       append([]int(nil), self.MaskedObjectives...),
+		ConstraintDependencies: append([]*ConstraintDependency(nil), self.ConstraintDependencies...),
+		Routes:                 append([]*OptimizationRoute(nil), self.Routes...),
+		TimingObjective:        self.TimingObjective,
+		PanicHandler:           self.PanicHandler,
+	}
+	output.bindSources(output.Variables)
+	return output
+}
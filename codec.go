@@ -0,0 +1,134 @@
+package autocode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const CONTENT_TYPE_JSON = "application/json"
+
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+type Codec interface {
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, value any) error
+	ContentType() string
+}
+
+type JsonCodec struct{}
+
+func NewJsonCodec() *JsonCodec {
+	return &JsonCodec{}
+}
+
+func (self *JsonCodec) Marshal(value any) (output []byte, err error) {
+	output, err = json.Marshal(value)
+	return output, err
+}
+
+func (self *JsonCodec) Unmarshal(data []byte, value any) (err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(value)
+	return err
+}
+
+func (self *JsonCodec) ContentType() string {
+	return CONTENT_TYPE_JSON
+}
+
+func (self *Optimization) RegisterCodec(codec Codec) {
+	self.Codecs = append(self.Codecs, codec)
+}
+
+func (self *Optimization) findCodec(contentType string) (output Codec) {
+	mediaType, _, parseErr := mime.ParseMediaType(contentType)
+	if parseErr != nil {
+		return output
+	}
+	if self.Codec != nil && self.Codec.ContentType() == mediaType {
+		output = self.Codec
+		return output
+	}
+	for _, codec := range self.Codecs {
+		if codec.ContentType() == mediaType {
+			output = codec
+			return output
+		}
+	}
+	return output
+}
+
+func (self *Optimization) requestCodec(reader *http.Request) (output Codec, err error) {
+	contentType := reader.Header.Get("Content-Type")
+	if contentType == "" {
+		output = self.Codec
+		return output, err
+	}
+	output = self.findCodec(contentType)
+	if output == nil {
+		err = fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+	}
+	return output, err
+}
+
+func (self *Optimization) responseCodec(reader *http.Request) (output Codec) {
+	for _, accepted := range strings.Split(reader.Header.Get("Accept"), ",") {
+		output = self.findCodec(strings.TrimSpace(accepted))
+		if output != nil {
+			return output
+		}
+	}
+	requestCodec, requestCodecErr := self.requestCodec(reader)
+	if requestCodecErr == nil && reader.ContentLength > 0 {
+		output = requestCodec
+		return output
+	}
+	output = self.Codec
+	return output
+}
+
+func (self *Optimization) decodeRequestBody(writer http.ResponseWriter, reader *http.Request, target any) (status int, err error) {
+	codec, codecErr := self.requestCodec(reader)
+	if codecErr != nil {
+		status = http.StatusUnsupportedMediaType
+		err = codecErr
+		return status, err
+	}
+	reader.Body = http.MaxBytesReader(writer, reader.Body, self.MaxRequestBodySize)
+	body, readErr := io.ReadAll(reader.Body)
+	if readErr != nil {
+		status = http.StatusBadRequest
+		if isMaxBytesError(readErr) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		err = readErr
+		return status, err
+	}
+	decodeErr := codec.Unmarshal(body, target)
+	if decodeErr != nil {
+		status = http.StatusBadRequest
+		err = decodeErr
+		return status, err
+	}
+	status = http.StatusOK
+	return status, err
+}
+
+func (self *Optimization) encodeResponseBody(writer http.ResponseWriter, reader *http.Request, value any) (err error) {
+	codec := self.responseCodec(reader)
+	body, marshalErr := codec.Marshal(value)
+	if marshalErr != nil {
+		err = marshalErr
+		return err
+	}
+	writer.Header().Set("Content-Type", codec.ContentType())
+	_, err = writer.Write(body)
+	return err
+}
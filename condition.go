@@ -0,0 +1,81 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// OptimizationCondition makes a variable active only while its parent variable is active and takes one of
+// Values. Conditions are evaluated on the client only; the backend still samples inactive variables.
+type OptimizationCondition struct {
+	ParentId string
+	Values   []*OptimizationValue
+}
+
+func (self *Optimization) AddCondition(variableId string, parentId string, parentValues ...any) (err error) {
+	_, variableExists := self.Variables[variableId]
+	if variableExists == false {
+		err = fmt.Errorf("variable not found: %s", variableId)
+		return err
+	}
+	if len(parentValues) == 0 {
+		err = fmt.Errorf("condition of %s needs at least one parent value", variableId)
+		return err
+	}
+	for ancestorId := parentId; ancestorId != ""; {
+		if ancestorId == variableId {
+			err = fmt.Errorf("condition of %s on %s is cyclic", variableId, parentId)
+			return err
+		}
+		ancestorCondition, ancestorConditionExists := self.Conditions[ancestorId]
+		if ancestorConditionExists == false {
+			break
+		}
+		ancestorId = ancestorCondition.ParentId
+	}
+	condition := &OptimizationCondition{
+		ParentId: parentId,
+	}
+	for _, parentValue := range parentValues {
+		value, valueErr := self.newVariableValue(parentId, parentValue)
+		if valueErr != nil {
+			err = fmt.Errorf("invalid condition of %s: %w", variableId, valueErr)
+			return err
+		}
+		condition.Values = append(condition.Values, value)
+	}
+	if self.Conditions == nil {
+		self.Conditions = map[string]*OptimizationCondition{}
+	}
+	self.Conditions[variableId] = condition
+	return err
+}
+
+// IsActive reports whether the condition declared on a variable holds for the current candidate, including the
+// conditions of its ancestors. Variables without a declared condition are always active.
+func (self *Optimization) IsActive(variableId string) (output bool) {
+	condition, conditionExists := self.Conditions[variableId]
+	if conditionExists == false {
+		output = true
+		return output
+	}
+	if self.IsActive(condition.ParentId) == false {
+		return output
+	}
+	value, valueExists := self.selectedValue(condition.ParentId)
+	if valueExists == false {
+		return output
+	}
+	parent := self.Variables[condition.ParentId]
+	_, isChoice := parent.(*OptimizationChoice)
+	for _, conditionValue := range condition.Values {
+		if isChoice == true {
+			output = value.Id == conditionValue.Id
+		} else {
+			output = self.resolveValue(parent, value) == self.resolveValue(parent, conditionValue)
+		}
+		if output == true {
+			return output
+		}
+	}
+	return output
+}
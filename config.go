@@ -0,0 +1,381 @@
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type OptimizationConfig struct {
+	ServerHost      string         `json:"server_host"`
+	ServerPort      int64          `json:"server_port"`
+	ClientPort      int64          `json:"client_port"`
+	Language        string         `json:"language"`
+	Variables       map[string]any `json:"variables"`
+	ExclusiveGroups [][]string     `json:"exclusive_groups"`
+	PinnedValues    map[string]any `json:"pinned_values"`
+}
+
+func valueConfigMap(value *OptimizationValue) (output map[string]any) {
+	output = value.Map()
+	switch typedData := value.Data.(type) {
+	case *OptimizationFunctionValue:
+		data := output["data"].(map[string]any)
+		data["error_potentiality"] = typedData.ErrorPotentiality
+		data["understandability"] = typedData.Understandability
+		data["complexity"] = typedData.Complexity
+		data["overall_maintainability"] = typedData.OverallMaintainability
+		data["modularity"] = typedData.Modularity
+		data["readability"] = typedData.Readability
+	case *OptimizationChoice:
+		output["data"] = variableConfigMap(typedData)
+	case *OptimizationGroup:
+		variables := map[string]any{}
+		for variableId, variable := range typedData.Variables {
+			variables[variableId] = variableConfigMap(variable)
+		}
+		output["data"].(map[string]any)["variables"] = variables
+	}
+	return output
+}
+
+func variableConfigMap(variable any) (output map[string]any) {
+	switch typedVariable := variable.(type) {
+	case *OptimizationBinary:
+		output = typedVariable.Map()
+	case *OptimizationInteger:
+		output = typedVariable.Map()
+	case *OptimizationReal:
+		output = typedVariable.Map()
+	case *OptimizationChoice:
+		output = typedVariable.Map()
+		options := map[string]any{}
+		for optionId, option := range typedVariable.Options {
+			options[optionId] = valueConfigMap(option)
+		}
+		output["options"] = options
+		output["option_id_separator"] = typedVariable.OptionIdSeparator()
+	default:
+		panic(fmt.Errorf("unsupported variable type: %T", variable))
+	}
+	return output
+}
+
+func (self *Optimization) MarshalConfig() (output []byte, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("failed to marshal config: %v", recovered)
+		}
+	}()
+	variables := map[string]any{}
+	for variableId, variable := range self.Variables {
+		variables[variableId] = variableConfigMap(variable)
+	}
+	pinnedValues := map[string]any{}
+	for variableId, pinnedValue := range self.PinnedValues {
+		pinnedValues[variableId] = valueConfigMap(pinnedValue)
+	}
+	config := &OptimizationConfig{
+		ServerHost:      self.ServerHost,
+		ServerPort:      self.ServerPort,
+		ClientPort:      self.ClientPort,
+		Language:        self.Language,
+		Variables:       variables,
+		ExclusiveGroups: self.ExclusiveGroups,
+		PinnedValues:    pinnedValues,
+	}
+	output, err = json.MarshalIndent(config, "", "  ")
+	return output, err
+}
+
+func loadValue(valueId string, value any) (output *OptimizationValue, err error) {
+	name := fmt.Sprintf("value %s", valueId)
+	valueMap, mapErr := asMap(value, name)
+	if mapErr != nil {
+		err = mapErr
+		return output, err
+	}
+	valueType, typeErr := asString(valueMap["type"], name+".type")
+	if typeErr != nil {
+		err = typeErr
+		return output, err
+	}
+	output = &OptimizationValue{
+		Id:   valueId,
+		Type: valueType,
+	}
+	switch valueType {
+	case VALUE_FUNCTION:
+		data, dataErr := asMap(valueMap["data"], name+".data")
+		if dataErr != nil {
+			err = dataErr
+			return output, err
+		}
+		functionName, _ := data["name"].(string)
+		functionSource, _ := data["string"].(string)
+		functionValue := &OptimizationFunctionValue{
+			name:   functionName,
+			source: functionSource,
+		}
+		functionValue.ErrorPotentiality, _ = asFloat64(data["error_potentiality"], "error_potentiality")
+		functionValue.Understandability, _ = asFloat64(data["understandability"], "understandability")
+		functionValue.Complexity, _ = asFloat64(data["complexity"], "complexity")
+		functionValue.OverallMaintainability, _ = asFloat64(data["overall_maintainability"], "overall_maintainability")
+		functionValue.Modularity, _ = asFloat64(data["modularity"], "modularity")
+		functionValue.Readability, _ = asFloat64(data["readability"], "readability")
+		output.Data = functionValue
+	case VALUE_APPLICATION:
+		data, dataErr := asMap(valueMap["data"], name+".data")
+		if dataErr != nil {
+			err = dataErr
+			return output, err
+		}
+		applicationName, _ := data["name"].(string)
+		output.Data = &OptimizationApplicationValue{
+			name: applicationName,
+		}
+	case VARIABLE_CHOICE:
+		output.Data, err = loadVariable(valueMap["data"])
+	case VALUE_CUSTOM:
+		output.Data, err = loadCustomOption(name+".data", valueMap["data"])
+	case VALUE_GROUP:
+		data, dataErr := asMap(valueMap["data"], name+".data")
+		if dataErr != nil {
+			err = dataErr
+			return output, err
+		}
+		variablesMap, variablesErr := asMap(data["variables"], name+".data.variables")
+		if variablesErr != nil {
+			err = variablesErr
+			return output, err
+		}
+		groupName, _ := data["name"].(string)
+		group := &OptimizationGroup{
+			Name:      groupName,
+			Variables: map[string]any{},
+		}
+		for variableId, variable := range variablesMap {
+			group.Variables[variableId], err = loadVariable(variable)
+			if err != nil {
+				return output, err
+			}
+		}
+		output.Data = group
+	case VALUE_INTEGER, VALUE_BYTES:
+		output.Data = valueMap["data"]
+		err = output.NormalizeData()
+	case VALUE_FLOAT:
+		output.Data, err = asFloat64(valueMap["data"], name+".data")
+	case VALUE_BOOLEAN:
+		output.Data, err = asBool(valueMap["data"], name+".data")
+	default:
+		err = fmt.Errorf("unsupported value type: %s", valueType)
+	}
+	return output, err
+}
+
+func loadVariable(variable any) (output any, err error) {
+	variableMap, mapErr := asMap(variable, "variable")
+	if mapErr != nil {
+		err = mapErr
+		return output, err
+	}
+	variableId, idErr := asString(variableMap["id"], "variable.id")
+	if idErr != nil {
+		err = idErr
+		return output, err
+	}
+	name := fmt.Sprintf("variables.%s", variableId)
+	variableType, typeErr := asString(variableMap["type"], name+".type")
+	if typeErr != nil {
+		err = typeErr
+		return output, err
+	}
+	switch variableType {
+	case VARIABLE_BINARY:
+		output = &OptimizationBinary{
+			OptimizationVariable: &OptimizationVariable{Id: variableId, Type: variableType},
+		}
+	case VARIABLE_INTEGER:
+		bounds, boundsErr := asIntegerBounds(variableMap["bounds"], name+".bounds")
+		if boundsErr != nil {
+			err = boundsErr
+			return output, err
+		}
+		integer := &OptimizationInteger{
+			OptimizationVariable: &OptimizationVariable{Id: variableId, Type: variableType},
+			Bounds:               bounds,
+		}
+		if variableMap["values"] != nil {
+			integer.Values, err = asIntegers(variableMap["values"], name+".values")
+		}
+		output = integer
+	case VARIABLE_REAL:
+		bounds, boundsErr := asBounds(variableMap["bounds"], name+".bounds")
+		if boundsErr != nil {
+			err = boundsErr
+			return output, err
+		}
+		output = &OptimizationReal{
+			OptimizationVariable: &OptimizationVariable{Id: variableId, Type: variableType},
+			Bounds:               bounds,
+		}
+	case VARIABLE_CHOICE:
+		optionsMap, optionsErr := asMap(variableMap["options"], name+".options")
+		if optionsErr != nil {
+			err = optionsErr
+			return output, err
+		}
+		options := map[string]*OptimizationValue{}
+		for optionId, option := range optionsMap {
+			options[optionId], err = loadValue(optionId, option)
+			if err != nil {
+				return output, err
+			}
+		}
+		separator := DEFAULT_OPTION_ID_SEPARATOR
+		_, separatorExists := variableMap["option_id_separator"]
+		if separatorExists == true {
+			separator, err = asString(variableMap["option_id_separator"], name+".option_id_separator")
+			if err != nil {
+				return output, err
+			}
+			err = ValidateOptionIdSeparator(separator)
+			if err != nil {
+				return output, err
+			}
+		}
+		output = &OptimizationChoice{
+			OptimizationVariable: &OptimizationVariable{Id: variableId, Type: variableType},
+			Options:              options,
+			optionIdSeparator:    separator,
+		}
+	default:
+		err = fmt.Errorf("unsupported variable type: %s", variableType)
+		return output, err
+	}
+	variableBase(output).Description, _ = variableMap["description"].(string)
+	metadata, metadataExists := variableMap["metadata"]
+	if metadataExists == true {
+		variableBase(output).Metadata, err = asStringMap(metadata, name+".metadata")
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, err
+}
+
+// LoadConfig rebuilds an Optimization from MarshalConfig output. Function and application options come back
+// as unbound stubs that must be re-bound with BindFunction and BindApplication before evaluation.
+func LoadConfig(data []byte, application OptimizationApplication) (output *Optimization, err error) {
+	config := &OptimizationConfig{}
+	unmarshalErr := NewJsonCodec().Unmarshal(data, config)
+	if unmarshalErr != nil {
+		err = unmarshalErr
+		return output, err
+	}
+	variables := []any{}
+	for _, variable := range config.Variables {
+		loadedVariable, loadErr := loadVariable(variable)
+		if loadErr != nil {
+			err = loadErr
+			return output, err
+		}
+		variables = append(variables, loadedVariable)
+	}
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("failed to load config: %v", recovered)
+		}
+	}()
+	output = NewOptimization(variables, application, config.ServerHost, config.ServerPort, config.ClientPort)
+	if config.Language != "" {
+		err = output.SetLanguage(config.Language)
+		if err != nil {
+			return output, err
+		}
+	}
+	for _, group := range config.ExclusiveGroups {
+		err = output.AddExclusiveGroup(group...)
+		if err != nil {
+			return output, err
+		}
+	}
+	for variableId, pinnedValue := range config.PinnedValues {
+		loadedValue, loadErr := loadValue(variableId, pinnedValue)
+		if loadErr != nil {
+			err = loadErr
+			return output, err
+		}
+		choice, isChoice := output.Variables[variableId].(*OptimizationChoice)
+		if isChoice == true {
+			pinnedValueMap, _ := pinnedValue.(map[string]any)
+			optionId, _ := pinnedValueMap["id"].(string)
+			option, optionExists := choice.Options[optionId]
+			if optionExists == false {
+				err = fmt.Errorf("pinned option not found: %s", optionId)
+				return output, err
+			}
+			loadedValue = option
+		}
+		if output.PinnedValues == nil {
+			output.PinnedValues = map[string]*OptimizationValue{}
+		}
+		output.PinnedValues[variableId] = loadedValue
+	}
+	return output, err
+}
+
+func forEachOption(variables map[string]any, callback func(option *OptimizationValue)) {
+	for _, variable := range variables {
+		choice, isChoice := variable.(*OptimizationChoice)
+		if isChoice == false {
+			continue
+		}
+		for _, option := range choice.Options {
+			callback(option)
+			nestedChoice, isNestedChoice := option.Data.(*OptimizationChoice)
+			if isNestedChoice == true {
+				forEachOption(map[string]any{nestedChoice.Id: nestedChoice}, callback)
+			}
+			group, isGroup := option.Data.(*OptimizationGroup)
+			if isGroup == true {
+				forEachOption(group.Variables, callback)
+			}
+		}
+	}
+}
+
+func (self *Optimization) BindFunction(name string, function FunctionValue) (output int) {
+	forEachOption(self.Variables, func(option *OptimizationValue) {
+		functionValue, isFunction := option.Data.(*OptimizationFunctionValue)
+		if isFunction == true && functionValue.isBound() == false && functionValue.name == name {
+			functionValue.Function = function
+			output++
+		}
+	})
+	return output
+}
+
+func (self *Optimization) BindContextFunction(name string, function ContextFunctionValue) (output int) {
+	forEachOption(self.Variables, func(option *OptimizationValue) {
+		functionValue, isFunction := option.Data.(*OptimizationFunctionValue)
+		if isFunction == true && functionValue.isBound() == false && functionValue.name == name {
+			functionValue.ContextFunction = function
+			output++
+		}
+	})
+	return output
+}
+
+func (self *Optimization) BindApplication(name string, application OptimizationApplication) (output int) {
+	forEachOption(self.Variables, func(option *OptimizationValue) {
+		applicationValue, isApplication := option.Data.(*OptimizationApplicationValue)
+		if isApplication == true && applicationValue.Application == nil && applicationValue.name == name {
+			applicationValue.Application = application
+			output++
+		}
+	})
+	return output
+}
@@ -0,0 +1,91 @@
+package autocode
+
+import (
+	"fmt"
+	"slices"
+)
+
+// NEUTRAL_CONSTRAINT_VALUE satisfies both g(x) <= 0 and h(x) == 0, so it stands in for inactive constraints.
+const NEUTRAL_CONSTRAINT_VALUE = 0.0
+
+type ConstraintPredicate func(optimization *Optimization) bool
+
+// OptimizationConstraintCondition makes the constraint at Index of the Kind slice apply only while Predicate
+// holds. Inactive constraints keep their slot and are reported as NEUTRAL_CONSTRAINT_VALUE.
+type OptimizationConstraintCondition struct {
+	Kind      string
+	Index     int
+	Predicate ConstraintPredicate
+}
+
+// WhenActive is a ConstraintPredicate that holds while the variable is active under its declared conditions.
+func WhenActive(variableId string) ConstraintPredicate {
+	return func(optimization *Optimization) bool {
+		return optimization.IsActive(variableId)
+	}
+}
+
+func (self *Optimization) AddConstraintCondition(kind string, index int, predicate ConstraintPredicate) (err error) {
+	if kind != RESPONSE_INEQUALITY && kind != RESPONSE_EQUALITY {
+		err = fmt.Errorf("unsupported constraint kind: %s", kind)
+		return err
+	}
+	if index < 0 {
+		err = fmt.Errorf("%s index must be non-negative: %d", kind, index)
+		return err
+	}
+	if predicate == nil {
+		err = fmt.Errorf("%s %d needs a predicate", kind, index)
+		return err
+	}
+	for _, condition := range self.ConstraintConditions {
+		if condition.Kind == kind && condition.Index == index {
+			err = fmt.Errorf("%s %d already has a condition", kind, index)
+			return err
+		}
+	}
+	self.ConstraintConditions = append(self.ConstraintConditions, &OptimizationConstraintCondition{
+		Kind:      kind,
+		Index:     index,
+		Predicate: predicate,
+	})
+	return err
+}
+
+func (self *Optimization) applyConstraintConditions(response *OptimizationEvaluateRunResponse) (err error) {
+	if response.Infeasible == true || len(self.ConstraintConditions) == 0 {
+		return err
+	}
+	response.InequalityConstraints = slices.Clone(response.InequalityConstraints)
+	response.EqualityConstraints = slices.Clone(response.EqualityConstraints)
+	for _, condition := range self.ConstraintConditions {
+		constraints := response.InequalityConstraints
+		if condition.Kind == RESPONSE_EQUALITY {
+			constraints = response.EqualityConstraints
+		}
+		if condition.Index >= len(constraints) {
+			err = fmt.Errorf("conditional %s %d has no slot, evaluation returned %d", condition.Kind, condition.Index, len(constraints))
+			return err
+		}
+		if condition.Predicate(self) == false {
+			constraints[condition.Index] = NEUTRAL_CONSTRAINT_VALUE
+		}
+	}
+	return err
+}
+
+// AddInequalityIf adds the inequality constraint when active and a neutral value in its slot otherwise.
+func (self *ResponseBuilder) AddInequalityIf(active bool, name string, value float64) *ResponseBuilder {
+	if active == false {
+		value = NEUTRAL_CONSTRAINT_VALUE
+	}
+	return self.AddInequality(name, value)
+}
+
+// AddEqualityIf adds the equality constraint when active and a neutral value in its slot otherwise.
+func (self *ResponseBuilder) AddEqualityIf(active bool, name string, value float64) *ResponseBuilder {
+	if active == false {
+		value = NEUTRAL_CONSTRAINT_VALUE
+	}
+	return self.AddEquality(name, value)
+}
@@ -0,0 +1,74 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// OptimizationCounts declares the shape of every evaluation before the first one runs, so the backend can size
+// its population or model at prepare time.
+type OptimizationCounts struct {
+	NumObjectives            int `json:"num_objectives"`
+	NumInequalityConstraints int `json:"num_inequality_constraints"`
+	NumEqualityConstraints   int `json:"num_equality_constraints"`
+}
+
+func (self *OptimizationCounts) Validate() (err error) {
+	if self.NumObjectives <= 0 {
+		err = fmt.Errorf("number of objectives must be positive: %d", self.NumObjectives)
+		return err
+	}
+	if self.NumInequalityConstraints < 0 {
+		err = fmt.Errorf("number of inequality constraints must be non-negative: %d", self.NumInequalityConstraints)
+		return err
+	}
+	if self.NumEqualityConstraints < 0 {
+		err = fmt.Errorf("number of equality constraints must be non-negative: %d", self.NumEqualityConstraints)
+		return err
+	}
+	return err
+}
+
+func (self *OptimizationCounts) Check(response *OptimizationEvaluateRunResponse) (err error) {
+	if len(response.Objectives) != self.NumObjectives {
+		err = fmt.Errorf("evaluation returned %d objectives, declared %d", len(response.Objectives), self.NumObjectives)
+		return err
+	}
+	if len(response.InequalityConstraints) != self.NumInequalityConstraints {
+		err = fmt.Errorf("evaluation returned %d inequality constraints, declared %d", len(response.InequalityConstraints), self.NumInequalityConstraints)
+		return err
+	}
+	if len(response.EqualityConstraints) != self.NumEqualityConstraints {
+		err = fmt.Errorf("evaluation returned %d equality constraints, declared %d", len(response.EqualityConstraints), self.NumEqualityConstraints)
+		return err
+	}
+	return err
+}
+
+func (self *Optimization) SetCounts(numObjectives int, numInequalityConstraints int, numEqualityConstraints int) (err error) {
+	counts := &OptimizationCounts{
+		NumObjectives:            numObjectives,
+		NumInequalityConstraints: numInequalityConstraints,
+		NumEqualityConstraints:   numEqualityConstraints,
+	}
+	err = counts.Validate()
+	if err != nil {
+		return err
+	}
+	self.Counts = counts
+	self.countsChecked = false
+	return err
+}
+
+// checkCounts compares the first feasible evaluation against the declared counts. Later evaluations are not
+// checked again.
+func (self *Optimization) checkCounts(response *OptimizationEvaluateRunResponse) (err error) {
+	if self.Counts == nil || self.countsChecked == true || response.Infeasible == true {
+		return err
+	}
+	err = self.Counts.Check(response)
+	if err != nil {
+		return err
+	}
+	self.countsChecked = true
+	return err
+}
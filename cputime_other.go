@@ -0,0 +1,14 @@
+//go:build !unix
+
+package autocode
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+func processCpuTime() (output time.Duration, err error) {
+	err = fmt.Errorf("cpu time is not supported on %s", runtime.GOOS)
+	return output, err
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package autocode
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// processCpuTime returns the user and system CPU time consumed by the whole process so far.
+func processCpuTime() (output time.Duration, err error) {
+	usage := &syscall.Rusage{}
+	usageErr := syscall.Getrusage(syscall.RUSAGE_SELF, usage)
+	if usageErr != nil {
+		err = fmt.Errorf("failed to read cpu time: %w", usageErr)
+		return output, err
+	}
+	output = time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+	return output, err
+}
@@ -0,0 +1,87 @@
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+const VALUE_CUSTOM = "custom"
+
+// Mapper lets a choice option carry a user type through the protocol as the map returned by Map. Types that
+// implement json.Marshaler instead are sent as their JSON encoding.
+type Mapper interface {
+	Map() map[string]any
+}
+
+// OptionFactory rebuilds a custom option from the decoded JSON of its mapped value.
+type OptionFactory = func(value any) (any, error)
+
+var optionFactories = struct {
+	factories map[string]OptionFactory
+	mutex     sync.RWMutex
+}{
+	factories: map[string]OptionFactory{},
+}
+
+// OptionKind names the type of a custom option on the wire and in the option factory registry.
+func OptionKind(data any) (output string) {
+	output = reflect.TypeOf(data).String()
+	return output
+}
+
+// RegisterOptionFactory registers how LoadConfig rebuilds custom options of a kind, as named by OptionKind.
+// Prepare keeps the local option data and does not need a factory.
+func RegisterOptionFactory(kind string, factory OptionFactory) {
+	optionFactories.mutex.Lock()
+	defer optionFactories.mutex.Unlock()
+	optionFactories.factories[kind] = factory
+}
+
+func customOptionMap(data any) (output map[string]any) {
+	var value any
+	switch typedData := data.(type) {
+	case Mapper:
+		value = typedData.Map()
+	case json.Marshaler:
+		marshaledData, marshalErr := typedData.MarshalJSON()
+		if marshalErr != nil {
+			panic(fmt.Errorf("failed to marshal custom option %s: %w", OptionKind(data), marshalErr))
+		}
+		value = json.RawMessage(marshaledData)
+	default:
+		panic(fmt.Errorf("custom option implements neither Mapper nor json.Marshaler: %T", data))
+	}
+	output = map[string]any{
+		"kind":  OptionKind(data),
+		"value": value,
+	}
+	return output
+}
+
+func loadCustomOption(name string, data any) (output any, err error) {
+	dataMap, mapErr := asMap(data, name)
+	if mapErr != nil {
+		err = mapErr
+		return output, err
+	}
+	kind, kindErr := asString(dataMap["kind"], name+".kind")
+	if kindErr != nil {
+		err = kindErr
+		return output, err
+	}
+	optionFactories.mutex.RLock()
+	factory, factoryExists := optionFactories.factories[kind]
+	optionFactories.mutex.RUnlock()
+	if factoryExists == false {
+		err = fmt.Errorf("no option factory registered for %s: %s", name, kind)
+		return output, err
+	}
+	output, err = factory(dataMap["value"])
+	if err != nil {
+		err = fmt.Errorf("failed to load custom option %s: %w", name, err)
+		return output, err
+	}
+	return output, err
+}
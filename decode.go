@@ -0,0 +1,388 @@
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+func variableBase(variable any) (output *OptimizationVariable) {
+	switch typedVariable := variable.(type) {
+	case *OptimizationBinary:
+		output = typedVariable.OptimizationVariable
+	case *OptimizationInteger:
+		output = typedVariable.OptimizationVariable
+	case *OptimizationReal:
+		output = typedVariable.OptimizationVariable
+	case *OptimizationChoice:
+		output = typedVariable.OptimizationVariable
+	default:
+		panic(fmt.Errorf("unsupported variable type: %T", variable))
+	}
+	return output
+}
+
+func asMap(value any, name string) (output map[string]any, err error) {
+	output, isMap := value.(map[string]any)
+	if isMap == false {
+		err = fmt.Errorf("%s must be an object: %T", name, value)
+	}
+	return output, err
+}
+
+func asString(value any, name string) (output string, err error) {
+	output, isString := value.(string)
+	if isString == false {
+		err = fmt.Errorf("%s must be a string: %T", name, value)
+	}
+	return output, err
+}
+
+func asStringMap(value any, name string) (output map[string]string, err error) {
+	valueMap, err := asMap(value, name)
+	if err != nil {
+		return output, err
+	}
+	output = make(map[string]string, len(valueMap))
+	for key, entry := range valueMap {
+		output[key], err = asString(entry, name+"."+key)
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, err
+}
+
+func asFloat64(value any, name string) (output float64, err error) {
+	switch typedValue := value.(type) {
+	case float64:
+		output = typedValue
+	case json.Number:
+		number, parseErr := typedValue.Float64()
+		if parseErr != nil {
+			err = fmt.Errorf("%s must be a number: %v", name, value)
+			return output, err
+		}
+		output = number
+	default:
+		err = fmt.Errorf("%s must be a number: %T", name, value)
+	}
+	return output, err
+}
+
+func asBool(value any, name string) (output bool, err error) {
+	output, isBool := value.(bool)
+	if isBool == false {
+		err = fmt.Errorf("%s must be a boolean: %T", name, value)
+	}
+	return output, err
+}
+
+func asBounds(value any, name string) (output [2]float64, err error) {
+	bounds, isList := value.([]any)
+	if isList == false || len(bounds) != 2 {
+		err = fmt.Errorf("%s must be a list of two numbers: %v", name, value)
+		return output, err
+	}
+	for index, bound := range bounds {
+		output[index], err = asFloat64(bound, fmt.Sprintf("%s[%d]", name, index))
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, err
+}
+
+// asInt64 decodes integers exactly: json.Number is parsed as an integer, falling back to an arbitrary precision
+// float for forms like "-2.0" or "1e3", and values outside int64 are rejected instead of wrapping.
+func asInt64(value any, name string) (output int64, err error) {
+	var number *big.Float
+	switch typedValue := value.(type) {
+	case int64:
+		output = typedValue
+		return output, err
+	case json.Number:
+		integer, parseErr := typedValue.Int64()
+		if parseErr == nil {
+			output = integer
+			return output, err
+		}
+		parsedNumber, _, floatParseErr := big.ParseFloat(typedValue.String(), 10, 256, big.ToNearestEven)
+		if floatParseErr != nil {
+			err = fmt.Errorf("%s must be an integer: %v", name, value)
+			return output, err
+		}
+		number = parsedNumber
+	case float64:
+		if math.IsInf(typedValue, 0) == true || math.IsNaN(typedValue) == true {
+			err = fmt.Errorf("%s must be an integer: %v", name, value)
+			return output, err
+		}
+		number = big.NewFloat(typedValue)
+	default:
+		err = fmt.Errorf("%s must be an integer: %T", name, value)
+		return output, err
+	}
+	if number.IsInt() == false {
+		err = fmt.Errorf("%s must be an integer: %v", name, value)
+		return output, err
+	}
+	integer, accuracy := number.Int64()
+	if accuracy != big.Exact {
+		err = fmt.Errorf("%s overflows int64: %v", name, value)
+		return output, err
+	}
+	output = integer
+	return output, err
+}
+
+func asIntegerBounds(value any, name string) (output [2]int64, err error) {
+	bounds, isList := value.([]any)
+	if isList == false || len(bounds) != 2 {
+		err = fmt.Errorf("%s must be a list of two integers: %v", name, value)
+		return output, err
+	}
+	for index, bound := range bounds {
+		output[index], err = asInt64(bound, fmt.Sprintf("%s[%d]", name, index))
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, err
+}
+
+func asIntegers(value any, name string) (output []int64, err error) {
+	values, isList := value.([]any)
+	if isList == false {
+		err = fmt.Errorf("%s must be a list of integers: %T", name, value)
+		return output, err
+	}
+	for index, element := range values {
+		number, numberErr := asInt64(element, fmt.Sprintf("%s[%d]", name, index))
+		if numberErr != nil {
+			err = numberErr
+			return output, err
+		}
+		output = append(output, number)
+	}
+	return output, err
+}
+
+func oldOptionData(variableId string, oldVariable any, optionId string) (output any, err error) {
+	if oldVariable == nil {
+		err = fmt.Errorf("unknown variable in prepare response: %s", variableId)
+		return output, err
+	}
+	oldChoice, isChoice := oldVariable.(*OptimizationChoice)
+	if isChoice == false {
+		err = fmt.Errorf("variable in prepare response is not a choice locally: %s", variableId)
+		return output, err
+	}
+	oldOption, oldOptionExists := oldChoice.Options[optionId]
+	if oldOptionExists == false {
+		err = fmt.Errorf("unknown option in prepare response: %s", optionId)
+		return output, err
+	}
+	output = oldOption.Data
+	return output, err
+}
+
+func decodeOption(variableId string, oldVariable any, optionId string, newOption any) (output *OptimizationValue, err error) {
+	name := fmt.Sprintf("variables.%s.options.%s", variableId, optionId)
+	newOptionMap, mapErr := asMap(newOption, name)
+	if mapErr != nil {
+		err = mapErr
+		return output, err
+	}
+	newOptionType, typeErr := asString(newOptionMap["type"], name+".type")
+	if typeErr != nil {
+		err = typeErr
+		return output, err
+	}
+	output = &OptimizationValue{
+		Id:   optionId,
+		Type: newOptionType,
+	}
+	if newOptionType == VALUE_FUNCTION {
+		newOptionData, dataErr := asMap(newOptionMap["data"], name+".data")
+		if dataErr != nil {
+			err = dataErr
+			return output, err
+		}
+		oldData, oldDataErr := oldOptionData(variableId, oldVariable, optionId)
+		if oldDataErr != nil {
+			err = oldDataErr
+			return output, err
+		}
+		oldOptionData, isFunction := oldData.(*OptimizationFunctionValue)
+		if isFunction == false {
+			err = fmt.Errorf("%s is not a function locally", name)
+			return output, err
+		}
+		metrics := map[string]float64{}
+		for _, metric := range []string{METRIC_ERROR_POTENTIALITY, METRIC_COMPLEXITY, METRIC_MODULARITY, METRIC_OVERALL_MAINTAINABILITY, METRIC_UNDERSTANDABILITY, METRIC_READABILITY} {
+			metrics[metric], err = asFloat64(newOptionData[metric], name+".data."+metric)
+			if err != nil {
+				return output, err
+			}
+		}
+		output.Data = &OptimizationFunctionValue{
+			Function:               oldOptionData.Function,
+			ContextFunction:        oldOptionData.ContextFunction,
+			Timeout:                oldOptionData.Timeout,
+			ErrorPotentiality:      metrics[METRIC_ERROR_POTENTIALITY],
+			Complexity:             metrics[METRIC_COMPLEXITY],
+			Modularity:             metrics[METRIC_MODULARITY],
+			OverallMaintainability: metrics[METRIC_OVERALL_MAINTAINABILITY],
+			Understandability:      metrics[METRIC_UNDERSTANDABILITY],
+			Readability:            metrics[METRIC_READABILITY],
+			name:                   oldOptionData.name,
+			source:                 oldOptionData.source,
+			sourceOwner:            oldOptionData.sourceOwner,
+		}
+	} else if newOptionType == VALUE_GROUP {
+		oldData, oldDataErr := oldOptionData(variableId, oldVariable, optionId)
+		if oldDataErr != nil {
+			err = oldDataErr
+			return output, err
+		}
+		oldGroup, isGroup := oldData.(*OptimizationGroup)
+		if isGroup == false {
+			err = fmt.Errorf("%s is not a group locally", name)
+			return output, err
+		}
+		output.Data, err = decodeGroup(name+".data", oldGroup, newOptionMap["data"])
+	} else if newOptionType == VALUE_APPLICATION || newOptionType == VARIABLE_CHOICE || newOptionType == VALUE_CUSTOM {
+		output.Data, err = oldOptionData(variableId, oldVariable, optionId)
+	} else if newOptionType == VALUE_INTEGER {
+		output.Data = newOptionMap["data"]
+		err = output.NormalizeData()
+	} else if newOptionType == VALUE_FLOAT {
+		output.Data, err = asFloat64(newOptionMap["data"], name+".data")
+	} else if newOptionType == VALUE_BOOLEAN {
+		output.Data, err = asBool(newOptionMap["data"], name+".data")
+	} else if newOptionType == VALUE_BYTES {
+		output.Data = newOptionMap["data"]
+		err = output.NormalizeData()
+	} else {
+		err = fmt.Errorf("unsupported newOption type: %s", newOptionType)
+	}
+	return output, err
+}
+
+func baseVariable(variableId string, oldVariable any, variableType string) (output any) {
+	if oldVariable != nil && getType(oldVariable) == variableType {
+		output = cloneVariable(oldVariable)
+		return output
+	}
+	variable := &OptimizationVariable{
+		Id:   variableId,
+		Type: variableType,
+	}
+	switch variableType {
+	case VARIABLE_CHOICE:
+		output = &OptimizationChoice{OptimizationVariable: variable, Options: map[string]*OptimizationValue{}}
+	case VARIABLE_INTEGER:
+		output = &OptimizationInteger{OptimizationVariable: variable}
+	case VARIABLE_REAL:
+		output = &OptimizationReal{OptimizationVariable: variable}
+	case VARIABLE_BINARY:
+		output = &OptimizationBinary{OptimizationVariable: variable}
+	}
+	return output
+}
+
+// decodeVariable merges the backend's version of a variable onto a copy of oldVariable, which is nil for a
+// variable that only the backend knows.
+func decodeVariable(variableId string, oldVariable any, newVariable any) (output any, err error) {
+	name := fmt.Sprintf("variables.%s", variableId)
+	newVariableMap, mapErr := asMap(newVariable, name)
+	if mapErr != nil {
+		err = mapErr
+		return output, err
+	}
+	newVariableType, typeErr := asString(newVariableMap["type"], name+".type")
+	if typeErr != nil {
+		err = typeErr
+		return output, err
+	}
+	output = baseVariable(variableId, oldVariable, newVariableType)
+	oldVariableExists := oldVariable != nil
+	switch variable := output.(type) {
+	case *OptimizationChoice:
+		newOptionsMap, optionsErr := asMap(newVariableMap["options"], name+".options")
+		if optionsErr != nil {
+			err = optionsErr
+			return output, err
+		}
+		// The backend's option set replaces the local one, so options it dropped do not survive the merge.
+		variable.Options = map[string]*OptimizationValue{}
+		for optionId, newOption := range newOptionsMap {
+			variable.Options[optionId], err = decodeOption(variableId, oldVariable, optionId, newOption)
+			if err != nil {
+				return output, err
+			}
+		}
+	case *OptimizationInteger:
+		inclusiveBounds, inclusiveBoundsExists := newVariableMap[INCLUSIVE_BOUNDS_KEY]
+		if inclusiveBoundsExists == true && inclusiveBounds != true {
+			err = fmt.Errorf("%s.%s must be true, integer bounds are inclusive at both ends: %v", name, INCLUSIVE_BOUNDS_KEY, inclusiveBounds)
+			return output, err
+		}
+		if newVariableMap["bounds"] != nil || oldVariableExists == false {
+			variable.Bounds, err = asIntegerBounds(newVariableMap["bounds"], name+".bounds")
+			if err != nil {
+				return output, err
+			}
+		}
+		if newVariableMap["values"] != nil {
+			variable.Values, err = asIntegers(newVariableMap["values"], name+".values")
+			if err != nil {
+				return output, err
+			}
+		}
+	case *OptimizationReal:
+		if newVariableMap["bounds"] != nil || oldVariableExists == false {
+			variable.Bounds, err = asBounds(newVariableMap["bounds"], name+".bounds")
+			if err != nil {
+				return output, err
+			}
+		}
+	case *OptimizationBinary:
+	default:
+		err = fmt.Errorf("unsupported variable type: %s", newVariableType)
+		return output, err
+	}
+	description, descriptionExists := newVariableMap["description"].(string)
+	if descriptionExists == true {
+		variableBase(output).Description = description
+	}
+	metadata, metadataExists := newVariableMap["metadata"]
+	if metadataExists == true {
+		variableBase(output).Metadata, err = asStringMap(metadata, name+".metadata")
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, err
+}
+
+func (self *Optimization) ApplyPrepareResponse(responseBody map[string]any) (err error) {
+	newVariablesMap, mapErr := asMap(responseBody["variables"], "variables")
+	if mapErr != nil {
+		err = mapErr
+		return err
+	}
+	newVariables := map[string]any{}
+	for variableId, newVariable := range newVariablesMap {
+		newVariables[variableId], err = decodeVariable(variableId, self.Variables[variableId], newVariable)
+		if err != nil {
+			return err
+		}
+	}
+	for variableId, newVariable := range newVariables {
+		self.Variables[variableId] = newVariable
+	}
+	return err
+}
@@ -0,0 +1,118 @@
+package autocode
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyPrepareResponseRetainsDescription(t *testing.T) {
+	optimization := NewOptimization([]any{
+		NewOptimizationReal("learning_rate", 0.0, 1.0, WithDescription("step size")),
+		NewOptimizationChoice("depth", []any{int64(2), int64(4)}, WithDescription("number of layers")),
+	}, nil, "localhost", 0, 0)
+	responseBody := map[string]any{
+		"variables": map[string]any{
+			"learning_rate": map[string]any{"type": VARIABLE_REAL, "bounds": []any{0.0, 0.5}},
+			"depth": map[string]any{
+				"type": VARIABLE_CHOICE,
+				"options": map[string]any{
+					"depth_1": map[string]any{"type": VALUE_INTEGER, "data": json.Number("4")},
+				},
+			},
+		},
+	}
+
+	err := optimization.ApplyPrepareResponse(responseBody)
+	if err != nil {
+		t.Fatalf("ApplyPrepareResponse() error = %v", err)
+	}
+
+	learningRate := optimization.Variables["learning_rate"].(*OptimizationReal)
+	if learningRate.Description != "step size" {
+		t.Errorf("learning_rate description = %q, want %q", learningRate.Description, "step size")
+	}
+	if learningRate.Bounds != [2]float64{0.0, 0.5} {
+		t.Errorf("learning_rate bounds = %v, want [0 0.5]", learningRate.Bounds)
+	}
+	choice := optimization.Variables["depth"].(*OptimizationChoice)
+	if choice.Description != "number of layers" {
+		t.Errorf("depth description = %q, want %q", choice.Description, "number of layers")
+	}
+	if len(choice.Options) != 1 || choice.Options["depth_1"] == nil {
+		t.Errorf("depth options = %v, want only depth_1", choice.SortedOptionIds())
+	}
+}
+
+func TestApplyPrepareResponseKeepsLargeIntegerBounds(t *testing.T) {
+	optimization := NewOptimization([]any{
+		NewOptimizationInteger("depth", 0, 10),
+	}, nil, "localhost", 0, 0)
+	responseBody := map[string]any{}
+	decodeErr := NewJsonCodec().Unmarshal([]byte(`{"variables": {"depth": {"type": "OptimizationInteger", "bounds": [-9007199254740993, 9007199254740993]}}}`), &responseBody)
+	if decodeErr != nil {
+		t.Fatalf("Unmarshal() error = %v", decodeErr)
+	}
+
+	err := optimization.ApplyPrepareResponse(responseBody)
+	if err != nil {
+		t.Fatalf("ApplyPrepareResponse() error = %v", err)
+	}
+
+	depth := optimization.Variables["depth"].(*OptimizationInteger)
+	want := [2]int64{-9007199254740993, 9007199254740993}
+	if depth.Bounds != want {
+		t.Errorf("depth bounds = %v, want %v", depth.Bounds, want)
+	}
+}
+
+func TestApplyPrepareResponseRejectsFractionalIntegerBounds(t *testing.T) {
+	optimization := NewOptimization([]any{
+		NewOptimizationInteger("depth", 0, 10),
+	}, nil, "localhost", 0, 0)
+	responseBody := map[string]any{
+		"variables": map[string]any{
+			"depth": map[string]any{"type": VARIABLE_INTEGER, "bounds": []any{json.Number("-1.5"), json.Number("4")}},
+		},
+	}
+
+	err := optimization.ApplyPrepareResponse(responseBody)
+	if err == nil {
+		t.Errorf("ApplyPrepareResponse() error = nil, want an error for bound -1.5")
+	}
+	depth := optimization.Variables["depth"].(*OptimizationInteger)
+	if depth.Bounds != [2]int64{0, 10} {
+		t.Errorf("depth bounds = %v, want the unchanged [0 10]", depth.Bounds)
+	}
+}
+
+func newDecodeTestOptimization() *Optimization {
+	group := NewOptimizationGroup("sgd", []any{
+		NewOptimizationReal("lr", 0.0, 1.0),
+		NewOptimizationBinary("nesterov"),
+	})
+	return NewOptimization([]any{
+		NewOptimizationBinary("enabled"),
+		NewOptimizationInteger("depth", -10, 10),
+		NewOptimizationReal("learning_rate", 0.0, 1.0),
+		NewOptimizationChoice("width", []any{int64(8), 0.5, true, []byte("raw")}),
+		NewOptimizationChoiceGroup("optimizer", []*OptimizationGroup{group}),
+	}, nil, "localhost", 0, 0)
+}
+
+func FuzzApplyPrepareResponse(f *testing.F) {
+	f.Add(`{"variables": {"depth": {"type": "OptimizationInteger", "bounds": [-2, 2]}}}`)
+	f.Add(`{"variables": {"learning_rate": {"type": "OptimizationReal", "bounds": [0.0, 0.5], "metadata": {"unit": "1"}}}}`)
+	f.Add(`{"variables": {"width": {"type": "OptimizationChoice", "options": {"width_0": {"type": "int", "data": 8}, "width_3": {"type": "bytes", "data": "cmF3"}}}}}`)
+	f.Add(`{"variables": {"optimizer": {"type": "OptimizationChoice", "options": {"optimizer_0": {"type": "OptimizationGroup", "data": {"name": "sgd", "variables": {"optimizer.sgd.lr": {"type": "OptimizationReal", "bounds": [0, 1]}}}}}}}}`)
+	f.Add(`{"variables": {"depth": {"type": "OptimizationInteger", "bounds": [1e400, "x"]}}}`)
+	f.Add(`{"variables": []}`)
+	f.Fuzz(func(t *testing.T, body string) {
+		responseBody := map[string]any{}
+		decodeErr := NewJsonCodec().Unmarshal([]byte(body), &responseBody)
+		if decodeErr != nil {
+			return
+		}
+		optimization := newDecodeTestOptimization()
+		_ = optimization.ApplyPrepareResponse(responseBody)
+	})
+}
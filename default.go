@@ -0,0 +1,42 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// DefaultValue returns a neutral value of a variable: false for binaries, the bound midpoint for integers and
+// reals (the set value closest to it for integer sets, preferring the lower one on ties) and the first option
+// of a choice in SortedOptionIds order.
+func DefaultValue(variable any) (output *OptimizationValue, err error) {
+	switch typedVariable := variable.(type) {
+	case *OptimizationBinary:
+		output = &OptimizationValue{Id: typedVariable.Id, Type: VALUE_BOOLEAN, Data: false}
+	case *OptimizationInteger:
+		midpoint := typedVariable.LowerBound() + typedVariable.Range()/2
+		if len(typedVariable.Values) > 0 {
+			closestValue := typedVariable.Values[0]
+			for _, value := range typedVariable.Values {
+				distance := max(value-midpoint, midpoint-value)
+				closestDistance := max(closestValue-midpoint, midpoint-closestValue)
+				if distance < closestDistance || (distance == closestDistance && value < closestValue) {
+					closestValue = value
+				}
+			}
+			midpoint = closestValue
+		}
+		output = &OptimizationValue{Id: typedVariable.Id, Type: VALUE_INTEGER, Data: midpoint}
+	case *OptimizationReal:
+		midpoint := typedVariable.LowerBound() + typedVariable.Range()/2
+		output = &OptimizationValue{Id: typedVariable.Id, Type: VALUE_FLOAT, Data: midpoint}
+	case *OptimizationChoice:
+		optionIds := typedVariable.SortedOptionIds()
+		if len(optionIds) == 0 {
+			err = fmt.Errorf("choice variable has no options: %s", typedVariable.Id)
+			return output, err
+		}
+		output = typedVariable.Options[optionIds[0]]
+	default:
+		err = fmt.Errorf("unsupported variable type: %T", variable)
+	}
+	return output, err
+}
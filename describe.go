@@ -0,0 +1,85 @@
+package autocode
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const DESCRIBE_INDENT = "  "
+
+func sortedKeys[Value any](values map[string]Value) (output []string) {
+	output = make([]string, 0, len(values))
+	for key := range values {
+		output = append(output, key)
+	}
+	sort.Strings(output)
+	return output
+}
+
+func describeValue(builder *strings.Builder, value *OptimizationValue, depth int) {
+	indent := strings.Repeat(DESCRIBE_INDENT, depth)
+	switch typedData := value.Data.(type) {
+	case *OptimizationFunctionValue:
+		fmt.Fprintf(builder, "%s%s: %s %s\n", indent, value.Id, value.Type, typedData.GetName())
+	case *OptimizationApplicationValue:
+		fmt.Fprintf(builder, "%s%s: %s %s\n", indent, value.Id, value.Type, typedData.GetName())
+	case *OptimizationChoice:
+		fmt.Fprintf(builder, "%s%s: %s\n", indent, value.Id, value.Type)
+		describeVariable(builder, typedData, depth+1)
+	case *OptimizationGroup:
+		fmt.Fprintf(builder, "%s%s: %s %s\n", indent, value.Id, value.Type, typedData.Name)
+		for _, variableId := range sortedKeys(typedData.Variables) {
+			describeVariable(builder, typedData.Variables[variableId], depth+1)
+		}
+	case []byte:
+		fmt.Fprintf(builder, "%s%s: %s %s\n", indent, value.Id, value.Type, base64.StdEncoding.EncodeToString(typedData))
+	default:
+		if value.Type == VALUE_CUSTOM {
+			fmt.Fprintf(builder, "%s%s: %s %s\n", indent, value.Id, value.Type, OptionKind(value.Data))
+		} else {
+			fmt.Fprintf(builder, "%s%s: %s %v\n", indent, value.Id, value.Type, value.Data)
+		}
+	}
+}
+
+func describeVariable(builder *strings.Builder, variable any, depth int) {
+	indent := strings.Repeat(DESCRIBE_INDENT, depth)
+	base := variableBase(variable)
+	fmt.Fprintf(builder, "%s%s: %s", indent, base.Id, base.Type)
+	switch typedVariable := variable.(type) {
+	case *OptimizationInteger:
+		if len(typedVariable.Values) > 0 {
+			fmt.Fprintf(builder, " %v", typedVariable.Values)
+		} else {
+			fmt.Fprintf(builder, " [%d, %d]", typedVariable.LowerBound(), typedVariable.UpperBound())
+		}
+	case *OptimizationReal:
+		fmt.Fprintf(builder, " [%v, %v]", typedVariable.LowerBound(), typedVariable.UpperBound())
+	}
+	if base.Description != "" {
+		fmt.Fprintf(builder, " - %s", base.Description)
+	}
+	builder.WriteString("\n")
+	for _, key := range sortedKeys(base.Metadata) {
+		fmt.Fprintf(builder, "%s%s%s = %s\n", indent, DESCRIBE_INDENT, key, base.Metadata[key])
+	}
+	choice, isChoice := variable.(*OptimizationChoice)
+	if isChoice == true {
+		for _, optionId := range sortedKeys(choice.Options) {
+			describeValue(builder, choice.Options[optionId], depth+1)
+		}
+	}
+}
+
+// Describe renders the search space as an indented tree sorted by id: each variable with its type and bounds,
+// and each choice with its options, function and application names, and nested variables.
+func (self *Optimization) Describe() (output string) {
+	builder := &strings.Builder{}
+	for _, variableId := range sortedKeys(self.Variables) {
+		describeVariable(builder, self.Variables[variableId], 0)
+	}
+	output = builder.String()
+	return output
+}
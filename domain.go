@@ -0,0 +1,90 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// Domain describes the valid values of a variable without type-switching on it. Kind is the VARIABLE_* type.
+// Binaries, integers and reals fill Min and Max, with a Step of 1 for the discrete ones and 0 for reals.
+// Discrete domains also fill IntegerMin and IntegerMax, which stay exact where Min and Max lose precision past
+// 2^53. Integer sets also list Values, and choices list their OptionIds in SortedOptionIds order.
+type Domain struct {
+	Kind       string
+	Min        float64
+	Max        float64
+	IntegerMin int64
+	IntegerMax int64
+	Step       float64
+	Values     []int64
+	OptionIds  []string
+}
+
+// Discrete reports whether the domain has a finite number of values.
+func (self Domain) Discrete() (output bool) {
+	output = self.Kind != VARIABLE_REAL
+	return output
+}
+
+type Domainer interface {
+	Domain() Domain
+}
+
+func (self *OptimizationBinary) Domain() (output Domain) {
+	output = Domain{Kind: VARIABLE_BINARY, Min: 0, Max: 1, IntegerMin: 0, IntegerMax: 1, Step: 1}
+	return output
+}
+
+func (self *OptimizationInteger) Domain() (output Domain) {
+	output = Domain{
+		Kind:       VARIABLE_INTEGER,
+		Min:        float64(self.LowerBound()),
+		Max:        float64(self.UpperBound()),
+		IntegerMin: self.LowerBound(),
+		IntegerMax: self.UpperBound(),
+		Step:       1,
+		Values:     append([]int64(nil), self.Values...),
+	}
+	return output
+}
+
+func (self *OptimizationReal) Domain() (output Domain) {
+	output = Domain{Kind: VARIABLE_REAL, Min: self.LowerBound(), Max: self.UpperBound()}
+	return output
+}
+
+func (self *OptimizationChoice) Domain() (output Domain) {
+	output = Domain{
+		Kind:       VARIABLE_CHOICE,
+		Min:        0,
+		Max:        float64(len(self.Options) - 1),
+		IntegerMin: 0,
+		IntegerMax: int64(len(self.Options) - 1),
+		Step:       1,
+		OptionIds:  self.SortedOptionIds(),
+	}
+	return output
+}
+
+func DomainOf(variable any) (output Domain, err error) {
+	domainer, isDomainer := variable.(Domainer)
+	if isDomainer == false {
+		err = fmt.Errorf("unsupported variable type: %T", variable)
+		return output, err
+	}
+	output = domainer.Domain()
+	return output, err
+}
+
+func (self *Optimization) Domains() (output map[string]Domain, err error) {
+	output = make(map[string]Domain, len(self.Variables))
+	for variableId, variable := range self.Variables {
+		domain, domainErr := DomainOf(variable)
+		if domainErr != nil {
+			err = fmt.Errorf("failed to describe %s: %w", variableId, domainErr)
+			output = nil
+			return output, err
+		}
+		output[variableId] = domain
+	}
+	return output, err
+}
@@ -0,0 +1,66 @@
+package autocode
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OptimizationEnvironment holds shared resources, such as a database handle or a compiler, that function values
+// reach through Env instead of rebuilding them on every call. Access to the map is synchronized, but resources
+// are handed out as is: a resource used by concurrent evaluations or parallel objectives must be safe for
+// concurrent use itself. The environment never closes resources; register their cleanup with OnShutdown.
+type OptimizationEnvironment struct {
+	values map[string]any
+	mutex  sync.RWMutex
+}
+
+func NewOptimizationEnvironment() *OptimizationEnvironment {
+	return &OptimizationEnvironment{
+		values: map[string]any{},
+	}
+}
+
+func (self *OptimizationEnvironment) Set(key string, value any) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.values[key] = value
+}
+
+func (self *OptimizationEnvironment) Get(key string) (output any, exists bool) {
+	self.mutex.RLock()
+	defer self.mutex.RUnlock()
+	output, exists = self.values[key]
+	return output, exists
+}
+
+func (self *OptimizationEnvironment) Delete(key string) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	delete(self.values, key)
+}
+
+// SetEnv stores a shared resource. It creates the environment on first use, so call it before serving starts.
+// Clones share the environment of the optimization they were cloned from.
+func (self *Optimization) SetEnv(key string, value any) {
+	if self.Environment == nil {
+		self.Environment = NewOptimizationEnvironment()
+	}
+	self.Environment.Set(key, value)
+}
+
+func (self *Optimization) LookupEnv(key string) (output any, exists bool) {
+	if self.Environment == nil {
+		return output, exists
+	}
+	output, exists = self.Environment.Get(key)
+	return output, exists
+}
+
+// Env returns a shared resource and panics when it was not set, like GetValue does for unknown variables.
+func (self *Optimization) Env(key string) (output any) {
+	output, exists := self.LookupEnv(key)
+	if exists == false {
+		panic(fmt.Errorf("environment value not found: %s", key))
+	}
+	return output
+}
@@ -0,0 +1,88 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// OptimizationEpsilonConstraint describes the epsilon-constraint method: the objective at ActiveIndex is kept and
+// every other objective is bounded by its threshold instead. Thresholds and the optional Directions are given
+// for every objective in declared order; the threshold of the active objective is ignored.
+type OptimizationEpsilonConstraint struct {
+	ActiveIndex int
+	Thresholds  []float64
+	Directions  []string
+}
+
+func NewOptimizationEpsilonConstraint(activeIndex int, thresholds []float64, directions ...string) (output *OptimizationEpsilonConstraint, err error) {
+	output = &OptimizationEpsilonConstraint{
+		ActiveIndex: activeIndex,
+		Thresholds:  append([]float64{}, thresholds...),
+		Directions:  append([]string(nil), directions...),
+	}
+	err = output.Validate()
+	if err != nil {
+		output = nil
+		return output, err
+	}
+	return output, err
+}
+
+func (self *OptimizationEpsilonConstraint) Validate() (err error) {
+	if self.ActiveIndex < 0 || self.ActiveIndex >= len(self.Thresholds) {
+		err = fmt.Errorf("active objective index %d is out of the %d thresholds", self.ActiveIndex, len(self.Thresholds))
+		return err
+	}
+	if len(self.Directions) > 0 && len(self.Directions) != len(self.Thresholds) {
+		err = fmt.Errorf("epsilon constraint has %d thresholds but %d directions", len(self.Thresholds), len(self.Directions))
+		return err
+	}
+	for index, direction := range self.Directions {
+		if direction != OBJECTIVE_MINIMIZE && direction != OBJECTIVE_MAXIMIZE {
+			err = fmt.Errorf("unsupported objective direction %d: %s", index, direction)
+			return err
+		}
+	}
+	return err
+}
+
+// Apply returns a response with the active objective as the only objective. Each other objective f with
+// threshold e is appended to the inequality constraints as f - e <= 0, or e - f <= 0 when maximized, after
+// the existing ones. Equality constraints and metadata are kept.
+func (self *OptimizationEpsilonConstraint) Apply(response *OptimizationEvaluateRunResponse) (output *OptimizationEvaluateRunResponse, err error) {
+	if len(response.Objectives) != len(self.Thresholds) {
+		err = fmt.Errorf("evaluation returned %d objectives for %d thresholds", len(response.Objectives), len(self.Thresholds))
+		return output, err
+	}
+	inequalityConstraints := append([]float64{}, response.InequalityConstraints...)
+	for index, objective := range response.Objectives {
+		if index == self.ActiveIndex {
+			continue
+		}
+		if len(self.Directions) > 0 && self.Directions[index] == OBJECTIVE_MAXIMIZE {
+			inequalityConstraints = append(inequalityConstraints, self.Thresholds[index]-objective)
+		} else {
+			inequalityConstraints = append(inequalityConstraints, objective-self.Thresholds[index])
+		}
+	}
+	output = &OptimizationEvaluateRunResponse{
+		Objectives:            []float64{response.Objectives[self.ActiveIndex]},
+		InequalityConstraints: inequalityConstraints,
+		EqualityConstraints:   append([]float64{}, response.EqualityConstraints...),
+		Metadata:              response.Metadata,
+		Infeasible:            response.Infeasible,
+	}
+	if len(response.ObjectiveStdErr) > self.ActiveIndex {
+		output.ObjectiveStdErr = []float64{response.ObjectiveStdErr[self.ActiveIndex]}
+	}
+	return output, err
+}
+
+// Counts returns the layout of the converted responses, for declaring with SetCounts.
+func (self *OptimizationEpsilonConstraint) Counts(numInequalityConstraints int, numEqualityConstraints int) (output *OptimizationCounts) {
+	output = &OptimizationCounts{
+		NumObjectives:            1,
+		NumInequalityConstraints: numInequalityConstraints + len(self.Thresholds) - 1,
+		NumEqualityConstraints:   numEqualityConstraints,
+	}
+	return output
+}
@@ -0,0 +1,70 @@
+package autocode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+type evaluateOptions struct {
+	defaults bool
+}
+
+type EvaluateOption = func(options *evaluateOptions)
+
+// WithDefaults fills every variable that is neither given nor pinned with its DefaultValue, including the
+// sub-variables of group options under their namespaced ids.
+func WithDefaults() EvaluateOption {
+	return func(options *evaluateOptions) {
+		options.defaults = true
+	}
+}
+
+// EvaluateWith runs a candidate given as plain Go values through RunEvaluation, so that repeats, parallel
+// objectives, penalties and normalization apply as they do to a candidate from the backend.
+func (self *Optimization) EvaluateWith(values map[string]any, options ...EvaluateOption) (output *OptimizationEvaluateRunResponse, err error) {
+	appliedOptions := &evaluateOptions{}
+	for _, option := range options {
+		option(appliedOptions)
+	}
+
+	variableValues := map[string]*OptimizationValue{}
+	for variableId, value := range values {
+		variableValue, variableValueErr := self.newVariableValue(variableId, value)
+		if variableValueErr != nil {
+			err = variableValueErr
+			return output, err
+		}
+		variableValues[variableId] = variableValue
+	}
+	if appliedOptions.defaults == true {
+		defaultedVariableIds := []string{}
+		for variableId, variable := range self.allVariables() {
+			_, valueExists := variableValues[variableId]
+			_, pinnedValueExists := self.PinnedValues[variableId]
+			if valueExists == true || pinnedValueExists == true {
+				continue
+			}
+			defaultValue, defaultValueErr := DefaultValue(variable)
+			if defaultValueErr != nil {
+				err = defaultValueErr
+				return output, err
+			}
+			variableValues[variableId] = defaultValue
+			defaultedVariableIds = append(defaultedVariableIds, variableId)
+		}
+		if len(defaultedVariableIds) > 0 {
+			sort.Strings(defaultedVariableIds)
+			self.Logger.Printf("defaulted variables: %v", defaultedVariableIds)
+		}
+	}
+
+	self.VariableValues = variableValues
+	self.ExecutedVariableValues = map[string]any{}
+	self.prepareResolvers()
+	output, err = self.RunEvaluation(context.Background())
+	if err != nil {
+		err = fmt.Errorf("failed to evaluate: %w", err)
+	}
+	return output, err
+}
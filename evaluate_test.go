@@ -0,0 +1,69 @@
+package autocode
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluateWithRunsEvaluationSteps(t *testing.T) {
+	application := &testApplication{
+		evaluate: func(ctx *Optimization) *OptimizationEvaluateRunResponse {
+			size := ctx.GetValue("size").(int64)
+			return &OptimizationEvaluateRunResponse{Objectives: []float64{float64(size)}}
+		},
+	}
+	optimization := NewOptimization([]any{NewOptimizationInteger("size", 0, 10)}, application, "localhost", 0, 0)
+	optimization.RegisterParallelObjectives(func(ctx context.Context, optimization *Optimization) (float64, error) {
+		return 8, nil
+	})
+	err := optimization.SetNormalization(&OptimizationNormalization{
+		Objectives: []*OptimizationScale{NewReferenceScale(2), NewReferenceScale(4)},
+	})
+	if err != nil {
+		t.Fatalf("SetNormalization() error = %v", err)
+	}
+	output, err := optimization.EvaluateWith(map[string]any{"size": int64(6)})
+	if err != nil {
+		t.Fatalf("EvaluateWith() error = %v", err)
+	}
+	if reflect.DeepEqual(output.Objectives, []float64{3, 2}) == false {
+		t.Errorf("EvaluateWith() objectives = %v, want [3 2]", output.Objectives)
+	}
+}
+
+func TestEvaluateWithDefaultsFillsGroupVariables(t *testing.T) {
+	group := NewOptimizationGroup("sgd", []any{
+		NewOptimizationReal("lr", 0.0, 1.0),
+		NewOptimizationBinary("nesterov"),
+	})
+	application := &testApplication{
+		evaluate: func(ctx *Optimization) *OptimizationEvaluateRunResponse {
+			return &OptimizationEvaluateRunResponse{Objectives: []float64{0}}
+		},
+	}
+	optimization := NewOptimization([]any{
+		NewOptimizationChoiceGroup("optimizer", []*OptimizationGroup{group}),
+	}, application, "localhost", 0, 0)
+	_, err := optimization.EvaluateWith(map[string]any{}, WithDefaults())
+	if err != nil {
+		t.Fatalf("EvaluateWith() error = %v", err)
+	}
+	tests := []struct {
+		variableId string
+		want       any
+	}{
+		{variableId: "optimizer.sgd.lr", want: 0.5},
+		{variableId: "optimizer.sgd.nesterov", want: false},
+	}
+	for _, test := range tests {
+		value, valueExists := optimization.VariableValues[test.variableId]
+		if valueExists == false {
+			t.Errorf("VariableValues[%s] is missing", test.variableId)
+			continue
+		}
+		if reflect.DeepEqual(value.Data, test.want) == false {
+			t.Errorf("VariableValues[%s] = %#v, want %#v", test.variableId, value.Data, test.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package autocode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+type OptimizationEvaluator interface {
+	Evaluate(ctx *Optimization) *OptimizationEvaluateRunResponse
+}
+
+type LocalEvaluator struct{}
+
+func NewLocalEvaluator() *LocalEvaluator {
+	return &LocalEvaluator{}
+}
+
+func (self *LocalEvaluator) Evaluate(ctx *Optimization) *OptimizationEvaluateRunResponse {
+	return ctx.Application.Evaluate(ctx)
+}
+
+type RemoteEvaluator struct {
+	WorkerUrls  []string
+	HttpClient  *http.Client
+	idleWorkers chan string
+}
+
+func NewRemoteEvaluator(workerUrls []string) *RemoteEvaluator {
+	if len(workerUrls) == 0 {
+		panic(fmt.Errorf("remote evaluator needs at least one worker"))
+	}
+	idleWorkers := make(chan string, len(workerUrls))
+	for _, workerUrl := range workerUrls {
+		idleWorkers <- workerUrl
+	}
+	return &RemoteEvaluator{
+		WorkerUrls:  workerUrls,
+		HttpClient:  NewHttpClient(),
+		idleWorkers: idleWorkers,
+	}
+}
+
+// Evaluate panics with the error of TryEvaluate, as the OptimizationEvaluator interface has no error result.
+func (self *RemoteEvaluator) Evaluate(ctx *Optimization) (output *OptimizationEvaluateRunResponse) {
+	output, err := self.TryEvaluate(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return output
+}
+
+// TryEvaluate prepares the candidate and its pinned values on an idle worker and runs it there. Requests use the
+// Codec, headers and retries of ctx.
+func (self *RemoteEvaluator) TryEvaluate(ctx *Optimization) (output *OptimizationEvaluateRunResponse, err error) {
+	workerUrl := <-self.idleWorkers
+	defer func() {
+		self.idleWorkers <- workerUrl
+	}()
+	target := requestTarget{
+		serverUrl:  workerUrl,
+		httpClient: self.HttpClient,
+	}
+
+	requestBody := &OptimizationEvaluatePrepareRequest{
+		VariableValues: ctx.VariableValues,
+		PinnedValues:   ctx.PinnedValues,
+		Seed:           ctx.EvaluationSeed,
+		TrialId:        ctx.EvaluationTrialId,
+	}
+	prepareErr := ctx.doRequestTo(context.Background(), target, http.MethodPost, "/apis/optimizations/evaluates/prepares", requestBody, nil)
+	if prepareErr != nil {
+		err = fmt.Errorf("failed to prepare worker evaluation on %s: %w", workerUrl, prepareErr)
+		return output, err
+	}
+
+	output = &OptimizationEvaluateRunResponse{}
+	runErr := ctx.doRequestTo(context.Background(), target, http.MethodGet, "/apis/optimizations/evaluates/runs", nil, output)
+	if runErr != nil {
+		err = fmt.Errorf("failed to run worker evaluation on %s: %w", workerUrl, runErr)
+		output = nil
+		return output, err
+	}
+	return output, err
+}
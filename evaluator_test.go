@@ -0,0 +1,60 @@
+package autocode
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newEvaluatorVariables() []any {
+	return []any{NewOptimizationInteger("size", 0, 10), NewOptimizationInteger("depth", 0, 10)}
+}
+
+func TestRemoteEvaluatorForwardsPinnedValues(t *testing.T) {
+	application := &testApplication{
+		evaluate: func(ctx *Optimization) *OptimizationEvaluateRunResponse {
+			size := ctx.GetValue("size").(int64)
+			depth := ctx.GetValue("depth").(int64)
+			return &OptimizationEvaluateRunResponse{Objectives: []float64{float64(size + 10*depth)}}
+		},
+	}
+	worker := NewOptimization(newEvaluatorVariables(), application, "localhost", 0, 0)
+	server := httptest.NewServer(worker.Handler())
+	defer server.Close()
+
+	optimization := NewOptimization(newEvaluatorVariables(), nil, "localhost", 0, 0)
+	err := optimization.Pin("depth", int64(3))
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+	optimization.VariableValues = map[string]*OptimizationValue{
+		"size": {Type: VALUE_INTEGER, Data: int64(2)},
+	}
+	evaluator := NewRemoteEvaluator([]string{server.URL})
+	output, err := evaluator.TryEvaluate(optimization)
+	if err != nil {
+		t.Fatalf("TryEvaluate() error = %v", err)
+	}
+	if reflect.DeepEqual(output.Objectives, []float64{32}) == false {
+		t.Errorf("TryEvaluate() objectives = %v, want [32]", output.Objectives)
+	}
+}
+
+func TestRemoteEvaluatorReturnsWorkerErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Error(writer, "worker unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	optimization := NewOptimization(newEvaluatorVariables(), nil, "localhost", 0, 0)
+	optimization.VariableValues = map[string]*OptimizationValue{}
+	evaluator := NewRemoteEvaluator([]string{server.URL})
+	output, err := evaluator.TryEvaluate(optimization)
+	if err == nil {
+		t.Fatalf("TryEvaluate() = %v, want an error", output)
+	}
+	if len(evaluator.idleWorkers) != 1 {
+		t.Errorf("idle workers = %d, want the worker returned", len(evaluator.idleWorkers))
+	}
+}
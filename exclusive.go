@@ -0,0 +1,72 @@
+package autocode
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ExclusiveGroupError struct {
+	Group             []string
+	ActiveVariableIds []string
+}
+
+func (self *ExclusiveGroupError) Error() string {
+	return fmt.Sprintf(
+		"exclusive group [%s] must have exactly one active variable, got %d: [%s]",
+		strings.Join(self.Group, ", "),
+		len(self.ActiveVariableIds),
+		strings.Join(self.ActiveVariableIds, ", "),
+	)
+}
+
+// AddExclusiveGroup requires exactly one of the given binaries to be true in every candidate. Choices are not
+// accepted since every choice always has a selected option.
+func (self *Optimization) AddExclusiveGroup(variableIds ...string) (err error) {
+	if len(variableIds) < 2 {
+		err = fmt.Errorf("exclusive group needs at least two variables: %v", variableIds)
+		return err
+	}
+	for _, variableId := range variableIds {
+		variable, variableExists := self.Variables[variableId]
+		if variableExists == false {
+			err = fmt.Errorf("variable not found: %s", variableId)
+			return err
+		}
+		_, isBinary := variable.(*OptimizationBinary)
+		if isBinary == false {
+			err = fmt.Errorf("exclusive group variable must be a binary: %s", variableId)
+			return err
+		}
+	}
+	self.ExclusiveGroups = append(self.ExclusiveGroups, append([]string{}, variableIds...))
+	return err
+}
+
+func (self *Optimization) isVariableActive(variableId string) (output bool) {
+	value, valueExists := self.selectedValue(variableId)
+	if valueExists == false || value == nil {
+		return output
+	}
+	data, isBoolean := value.Data.(bool)
+	output = isBoolean && data
+	return output
+}
+
+func (self *Optimization) ValidateExclusiveGroups() (err error) {
+	for _, group := range self.ExclusiveGroups {
+		activeVariableIds := []string{}
+		for _, variableId := range group {
+			if self.isVariableActive(variableId) == true {
+				activeVariableIds = append(activeVariableIds, variableId)
+			}
+		}
+		if len(activeVariableIds) != 1 {
+			err = &ExclusiveGroupError{
+				Group:             group,
+				ActiveVariableIds: activeVariableIds,
+			}
+			return err
+		}
+	}
+	return err
+}
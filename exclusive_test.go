@@ -0,0 +1,82 @@
+package autocode
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddExclusiveGroupRejectsChoices(t *testing.T) {
+	variables := []any{
+		NewOptimizationBinary("first"),
+		NewOptimizationChoice("second", []any{int64(1), int64(2)}),
+	}
+	optimization := NewOptimization(variables, nil, "localhost", 0, 0)
+	err := optimization.AddExclusiveGroup("first", "second")
+	if err == nil {
+		t.Fatalf("AddExclusiveGroup() error = nil, want an error for a choice")
+	}
+	if len(optimization.ExclusiveGroups) != 0 {
+		t.Errorf("ExclusiveGroups = %v, want none", optimization.ExclusiveGroups)
+	}
+}
+
+func TestValidateExclusiveGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		first  bool
+		second bool
+		valid  bool
+	}{
+		{name: "none", first: false, second: false, valid: false},
+		{name: "first", first: true, second: false, valid: true},
+		{name: "second", first: false, second: true, valid: true},
+		{name: "both", first: true, second: true, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			variables := []any{NewOptimizationBinary("first"), NewOptimizationBinary("second")}
+			optimization := NewOptimization(variables, nil, "localhost", 0, 0)
+			err := optimization.AddExclusiveGroup("first", "second")
+			if err != nil {
+				t.Fatalf("AddExclusiveGroup() error = %v", err)
+			}
+			optimization.VariableValues = map[string]*OptimizationValue{
+				"first":  {Type: VALUE_BOOLEAN, Data: test.first},
+				"second": {Type: VALUE_BOOLEAN, Data: test.second},
+			}
+			err = optimization.ValidateExclusiveGroups()
+			groupErr := &ExclusiveGroupError{}
+			if test.valid == true && err != nil {
+				t.Fatalf("ValidateExclusiveGroups() error = %v", err)
+			}
+			if test.valid == false && errors.As(err, &groupErr) == false {
+				t.Fatalf("ValidateExclusiveGroups() error = %v, want an ExclusiveGroupError", err)
+			}
+		})
+	}
+}
+
+func TestValidateExclusiveGroupsResolvesPins(t *testing.T) {
+	variables := []any{NewOptimizationBinary("first"), NewOptimizationBinary("second")}
+	optimization := NewOptimization(variables, nil, "localhost", 0, 0)
+	err := optimization.AddExclusiveGroup("first", "second")
+	if err != nil {
+		t.Fatalf("AddExclusiveGroup() error = %v", err)
+	}
+	err = optimization.Pin("first", true)
+	if err != nil {
+		t.Fatalf("Pin() error = %v", err)
+	}
+	optimization.VariableValues = map[string]*OptimizationValue{
+		"second": {Type: VALUE_BOOLEAN, Data: false},
+	}
+	err = optimization.ValidateExclusiveGroups()
+	if err != nil {
+		t.Fatalf("ValidateExclusiveGroups() error = %v", err)
+	}
+	optimization.VariableValues["second"] = &OptimizationValue{Type: VALUE_BOOLEAN, Data: true}
+	err = optimization.ValidateExclusiveGroups()
+	if err == nil {
+		t.Fatalf("ValidateExclusiveGroups() error = nil, want an error for a pinned and a selected variable")
+	}
+}
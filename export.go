@@ -0,0 +1,119 @@
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func integerSetChoices(values []int64) (output []any) {
+	for _, value := range values {
+		output = append(output, value)
+	}
+	return output
+}
+
+func optionIdChoices(optionIds []string) (output []any) {
+	for _, optionId := range optionIds {
+		output = append(output, optionId)
+	}
+	return output
+}
+
+// optunaDistribution follows the {"name", "attributes"} shape read by optuna.distributions.json_to_distribution.
+func optunaDistribution(variable any) (output map[string]any, err error) {
+	domain, domainErr := DomainOf(variable)
+	if domainErr != nil {
+		err = domainErr
+		return output, err
+	}
+	switch domain.Kind {
+	case VARIABLE_BINARY:
+		output = map[string]any{
+			"name":       "CategoricalDistribution",
+			"attributes": map[string]any{"choices": []any{false, true}},
+		}
+	case VARIABLE_INTEGER:
+		if len(domain.Values) > 0 {
+			output = map[string]any{
+				"name":       "CategoricalDistribution",
+				"attributes": map[string]any{"choices": integerSetChoices(domain.Values)},
+			}
+		} else {
+			output = map[string]any{
+				"name":       "IntDistribution",
+				"attributes": map[string]any{"low": domain.IntegerMin, "high": domain.IntegerMax, "log": false, "step": 1},
+			}
+		}
+	case VARIABLE_REAL:
+		output = map[string]any{
+			"name":       "FloatDistribution",
+			"attributes": map[string]any{"low": domain.Min, "high": domain.Max, "log": false, "step": nil},
+		}
+	case VARIABLE_CHOICE:
+		output = map[string]any{
+			"name":       "CategoricalDistribution",
+			"attributes": map[string]any{"choices": optionIdChoices(domain.OptionIds)},
+		}
+	default:
+		err = fmt.Errorf("unsupported variable kind: %s", domain.Kind)
+	}
+	return output, err
+}
+
+// pymooVariable follows the constructor arguments of pymoo.core.variable: Binary, Integer(bounds), Real(bounds)
+// and Choice(options).
+func pymooVariable(variable any) (output map[string]any, err error) {
+	domain, domainErr := DomainOf(variable)
+	if domainErr != nil {
+		err = domainErr
+		return output, err
+	}
+	switch domain.Kind {
+	case VARIABLE_BINARY:
+		output = map[string]any{"type": "Binary"}
+	case VARIABLE_INTEGER:
+		if len(domain.Values) > 0 {
+			output = map[string]any{"type": "Choice", "options": integerSetChoices(domain.Values)}
+		} else {
+			output = map[string]any{"type": "Integer", "bounds": [2]int64{domain.IntegerMin, domain.IntegerMax}}
+		}
+	case VARIABLE_REAL:
+		output = map[string]any{"type": "Real", "bounds": [2]float64{domain.Min, domain.Max}}
+	case VARIABLE_CHOICE:
+		output = map[string]any{"type": "Choice", "options": optionIdChoices(domain.OptionIds)}
+	default:
+		err = fmt.Errorf("unsupported variable kind: %s", domain.Kind)
+	}
+	return output, err
+}
+
+func (self *Optimization) exportVariables(convert func(variable any) (map[string]any, error)) (output []byte, err error) {
+	variables := self.allVariables()
+	exportedVariables := map[string]any{}
+	for _, variableId := range sortedKeys(variables) {
+		exportedVariables[variableId], err = convert(variables[variableId])
+		if err != nil {
+			err = fmt.Errorf("failed to export %s: %w", variableId, err)
+			return output, err
+		}
+	}
+	output, err = json.MarshalIndent(exportedVariables, "", "  ")
+	return output, err
+}
+
+// ExportOptunaDistributions returns a JSON object mapping every variable id to an optuna distribution in the
+// json_to_distribution format: binaries and choices become CategoricalDistribution over false/true and the
+// option ids, integer sets CategoricalDistribution over their values, integers IntDistribution and reals
+// FloatDistribution. Sub-variables of group options are included under their namespaced ids.
+func (self *Optimization) ExportOptunaDistributions() (output []byte, err error) {
+	output, err = self.exportVariables(optunaDistribution)
+	return output, err
+}
+
+// ExportPymooVariables returns a JSON object mapping every variable id to {"type": "Binary"}, {"type":
+// "Integer", "bounds": [l, u]}, {"type": "Real", "bounds": [l, u]} or {"type": "Choice", "options": [...]}, the
+// arguments of the pymoo mixed-variable classes. Choices list option ids and integer sets their values.
+func (self *Optimization) ExportPymooVariables() (output []byte, err error) {
+	output, err = self.exportVariables(pymooVariable)
+	return output, err
+}
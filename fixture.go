@@ -0,0 +1,194 @@
+package autocode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+const FIXTURE_RECORD = "record"
+const FIXTURE_REPLAY = "replay"
+
+// FixtureInteraction keeps JSON bodies as they are and other bodies base64-encoded in the raw fields.
+type FixtureInteraction struct {
+	Method              string          `json:"method"`
+	Path                string          `json:"path"`
+	RequestBody         json.RawMessage `json:"request_body,omitempty"`
+	RawRequestBody      []byte          `json:"raw_request_body,omitempty"`
+	StatusCode          int             `json:"status_code"`
+	ResponseContentType string          `json:"response_content_type,omitempty"`
+	ResponseBody        json.RawMessage `json:"response_body,omitempty"`
+	RawResponseBody     []byte          `json:"raw_response_body,omitempty"`
+}
+
+type FixtureTransport struct {
+	Mode      string
+	Path      string
+	Transport http.RoundTripper
+	mutex     sync.Mutex
+}
+
+func NewFixtureTransport(mode string, path string) *FixtureTransport {
+	if mode != FIXTURE_RECORD && mode != FIXTURE_REPLAY {
+		panic(fmt.Errorf("unsupported fixture mode: %s", mode))
+	}
+	return &FixtureTransport{
+		Mode:      mode,
+		Path:      path,
+		Transport: http.DefaultTransport,
+	}
+}
+
+func fixtureKey(method string, path string) (output string) {
+	output = fmt.Sprintf("%s %s", method, path)
+	return output
+}
+
+func splitFixtureBody(body []byte) (jsonBody json.RawMessage, rawBody []byte) {
+	if len(body) == 0 {
+		return jsonBody, rawBody
+	}
+	if json.Valid(body) == true {
+		jsonBody = json.RawMessage(body)
+		return jsonBody, rawBody
+	}
+	rawBody = body
+	return jsonBody, rawBody
+}
+
+func (self *FixtureInteraction) responseBody() (output []byte) {
+	if self.RawResponseBody != nil {
+		output = self.RawResponseBody
+		return output
+	}
+	output = self.ResponseBody
+	return output
+}
+
+func (self *FixtureTransport) load() (output map[string]*FixtureInteraction, err error) {
+	output = map[string]*FixtureInteraction{}
+	fixtureJson, readErr := os.ReadFile(self.Path)
+	if os.IsNotExist(readErr) == true {
+		return output, err
+	}
+	if readErr != nil {
+		err = readErr
+		return output, err
+	}
+	interactions := []*FixtureInteraction{}
+	unmarshalErr := json.Unmarshal(fixtureJson, &interactions)
+	if unmarshalErr != nil {
+		err = fmt.Errorf("invalid fixture %s: %w", self.Path, unmarshalErr)
+		return output, err
+	}
+	for _, interaction := range interactions {
+		output[fixtureKey(interaction.Method, interaction.Path)] = interaction
+	}
+	return output, err
+}
+
+func (self *FixtureTransport) save(interaction *FixtureInteraction) (err error) {
+	interactionsByKey, loadErr := self.load()
+	if loadErr != nil {
+		err = loadErr
+		return err
+	}
+	interactionsByKey[fixtureKey(interaction.Method, interaction.Path)] = interaction
+	keys := []string{}
+	for key := range interactionsByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	interactions := []*FixtureInteraction{}
+	for _, key := range keys {
+		interactions = append(interactions, interactionsByKey[key])
+	}
+	fixtureJson, jsonErr := json.MarshalIndent(interactions, "", "  ")
+	if jsonErr != nil {
+		err = jsonErr
+		return err
+	}
+	err = os.WriteFile(self.Path, fixtureJson, 0644)
+	return err
+}
+
+func (self *FixtureTransport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+
+	if self.Mode == FIXTURE_REPLAY {
+		interactions, loadErr := self.load()
+		if loadErr != nil {
+			err = loadErr
+			return response, err
+		}
+		interaction, interactionExists := interactions[fixtureKey(request.Method, request.URL.Path)]
+		if interactionExists == false {
+			err = fmt.Errorf("fixture interaction not found: %s", fixtureKey(request.Method, request.URL.Path))
+			return response, err
+		}
+		contentType := interaction.ResponseContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		response = &http.Response{
+			Status:     fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+			StatusCode: interaction.StatusCode,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(bytes.NewReader(interaction.responseBody())),
+			Request:    request,
+		}
+		return response, err
+	}
+
+	requestBody := []byte{}
+	if request.Body != nil {
+		readBody, readErr := io.ReadAll(request.Body)
+		if readErr != nil {
+			err = readErr
+			return response, err
+		}
+		requestBody = readBody
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+	response, err = self.Transport.RoundTrip(request)
+	if err != nil {
+		return response, err
+	}
+	responseBody, readErr := io.ReadAll(response.Body)
+	response.Body.Close()
+	if readErr != nil {
+		err = readErr
+		return response, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+	interaction := &FixtureInteraction{
+		Method:              request.Method,
+		Path:                request.URL.Path,
+		StatusCode:          response.StatusCode,
+		ResponseContentType: response.Header.Get("Content-Type"),
+	}
+	interaction.RequestBody, interaction.RawRequestBody = splitFixtureBody(requestBody)
+	interaction.ResponseBody, interaction.RawResponseBody = splitFixtureBody(responseBody)
+	err = self.save(interaction)
+	return response, err
+}
+
+func (self *Optimization) UseFixture(mode string, path string) {
+	transport := NewFixtureTransport(mode, path)
+	if self.HttpClient.Transport != nil {
+		transport.Transport = self.HttpClient.Transport
+	}
+	self.HttpClient = &http.Client{
+		Transport: transport,
+		Timeout:   self.HttpClient.Timeout,
+	}
+}
@@ -0,0 +1,49 @@
+package autocode
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixtureReplaysRecordedBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "text", contentType: "text/plain; charset=utf-8", body: "backend \"busy\"\n"},
+		{name: "text without charset", contentType: "text/plain", body: "{not json"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Content-Type", test.contentType)
+				io.WriteString(writer, test.body)
+			}))
+			defer server.Close()
+			fixturePath := filepath.Join(t.TempDir(), "fixture.json")
+
+			for _, mode := range []string{FIXTURE_RECORD, FIXTURE_REPLAY} {
+				client := &http.Client{Transport: NewFixtureTransport(mode, fixturePath)}
+				response, err := client.Post(server.URL+"/apis/optimizations/asks", "text/plain", nil)
+				if err != nil {
+					t.Fatalf("%s: Post() error = %v", mode, err)
+				}
+				body, readErr := io.ReadAll(response.Body)
+				response.Body.Close()
+				if readErr != nil {
+					t.Fatalf("%s: ReadAll() error = %v", mode, readErr)
+				}
+				if string(body) != test.body {
+					t.Errorf("%s: body = %q, want %q", mode, body, test.body)
+				}
+				if response.Header.Get("Content-Type") != test.contentType {
+					t.Errorf("%s: Content-Type = %q, want %q", mode, response.Header.Get("Content-Type"), test.contentType)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// OptimizationGroup is a choice option made of named sub-variables that are resolved together when the option
+// is selected, e.g. an "sgd" option activating its own learning rate and momentum. Variables is keyed by the ids
+// the sub-variables were declared with. Once the group is an option of a choice, the sub-variables are sampled
+// by the backend under ids namespaced as "<choice>.<group>.<id>", e.g. "optimizer.sgd.lr".
+type OptimizationGroup struct {
+	Name      string
+	Variables map[string]any
+}
+
+func NewOptimizationGroup(name string, variables []any) *OptimizationGroup {
+	transformedVariables := map[string]any{}
+	for _, variable := range variables {
+		variableId := getFieldValue(variable, "Id").(string)
+		mustValidateId(variableId)
+		_, variableExists := transformedVariables[variableId]
+		if variableExists == true {
+			panic(fmt.Errorf("duplicate variable id in group %s: %s", name, variableId))
+		}
+		transformedVariables[variableId] = variable
+	}
+	return &OptimizationGroup{
+		Name:      name,
+		Variables: transformedVariables,
+	}
+}
+
+func (self *OptimizationGroup) Map() (output map[string]any) {
+	variables := map[string]any{}
+	for _, variable := range self.Variables {
+		variables[variableBase(variable).Id] = variableMap(variable)
+	}
+	output = map[string]any{
+		"name":      self.Name,
+		"variables": variables,
+	}
+	return output
+}
+
+func (self *OptimizationGroup) Clone() *OptimizationGroup {
+	variables := map[string]any{}
+	for variableId, variable := range self.Variables {
+		variables[variableId] = cloneVariable(variable)
+	}
+	return &OptimizationGroup{
+		Name:      self.Name,
+		Variables: variables,
+	}
+}
+
+func NewOptimizationChoiceGroup(id string, groups []*OptimizationGroup, variableOptions ...VariableOption) *OptimizationChoice {
+	options := []any{}
+	for _, group := range groups {
+		options = append(options, group)
+	}
+	return NewOptimizationChoice(id, options, variableOptions...)
+}
+
+// bindGroups replaces the group options of a choice with copies whose sub-variables carry ids namespaced by the
+// choice and the group, so sub-variables of different groups and choices cannot collide.
+func bindGroups(choice *OptimizationChoice) {
+	groupNames := map[string]bool{}
+	for _, optionId := range choice.SortedOptionIds() {
+		option := choice.Options[optionId]
+		group, isGroup := option.Data.(*OptimizationGroup)
+		if isGroup == false {
+			continue
+		}
+		if groupNames[group.Name] == true {
+			panic(fmt.Errorf("duplicate group name in choice %s: %s", choice.Id, group.Name))
+		}
+		groupNames[group.Name] = true
+		boundGroup := group.Clone()
+		for variableId, variable := range boundGroup.Variables {
+			namespacedId := fmt.Sprintf("%s.%s.%s", choice.Id, group.Name, variableId)
+			mustValidateId(namespacedId)
+			variableBase(variable).Id = namespacedId
+			nestedChoice, isNestedChoice := variable.(*OptimizationChoice)
+			if isNestedChoice == true {
+				bindGroups(nestedChoice)
+			}
+		}
+		option.Data = boundGroup
+	}
+}
+
+func collectGroupVariables(variable any, output map[string]any) {
+	choice, isChoice := variable.(*OptimizationChoice)
+	if isChoice == false {
+		return
+	}
+	for _, option := range choice.Options {
+		group, isGroup := option.Data.(*OptimizationGroup)
+		if isGroup == false {
+			continue
+		}
+		for _, groupVariable := range group.Variables {
+			output[variableBase(groupVariable).Id] = groupVariable
+			collectGroupVariables(groupVariable, output)
+		}
+	}
+}
+
+// groupVariables returns the sub-variables of group options, keyed by their namespaced ids.
+func (self *Optimization) groupVariables() (output map[string]any) {
+	output = map[string]any{}
+	for _, variable := range self.Variables {
+		collectGroupVariables(variable, output)
+	}
+	return output
+}
+
+// allVariables returns the declared variables together with the sub-variables of group options, which the
+// backend samples alongside them.
+func (self *Optimization) allVariables() (output map[string]any) {
+	output = self.groupVariables()
+	for variableId, variable := range self.Variables {
+		output[variableId] = variable
+	}
+	return output
+}
+
+// findVariable looks a variable up by id among the declared variables and the sub-variables of group options.
+func (self *Optimization) findVariable(variableId string) (output any, ok bool) {
+	output, ok = self.Variables[variableId]
+	if ok == true {
+		return output, ok
+	}
+	output, ok = self.groupVariables()[variableId]
+	return output, ok
+}
+
+// checkGroupVariableIds reports a sub-variable of a group option whose namespaced id is also used by another
+// variable.
+func checkGroupVariableIds(variables map[string]any) (err error) {
+	seenIds := map[string]bool{}
+	for variableId := range variables {
+		seenIds[variableId] = true
+	}
+	for _, variableId := range sortedKeys(variables) {
+		groupVariables := map[string]any{}
+		collectGroupVariables(variables[variableId], groupVariables)
+		for _, groupVariableId := range sortedKeys(groupVariables) {
+			if seenIds[groupVariableId] == true {
+				err = fmt.Errorf("group variable id collides with another variable: %s", groupVariableId)
+				return err
+			}
+			seenIds[groupVariableId] = true
+		}
+	}
+	return err
+}
+
+func (self *Optimization) resolveGroup(group *OptimizationGroup, arguments ...any) (output map[string]any) {
+	output = map[string]any{}
+	for variableId, variable := range group.Variables {
+		groupVariableId := variableBase(variable).Id
+		value, valueExists := self.selectedValue(groupVariableId)
+		if valueExists == false {
+			panic(fmt.Errorf("group %s variable value not found: %s", group.Name, groupVariableId))
+		}
+		output[variableId] = self.resolveValue(variable, value, arguments...)
+	}
+	return output
+}
+
+// decodeGroup merges the backend's sub-variables, keyed by their namespaced ids, onto the local group.
+// Sub-variables the backend omitted are kept as they are.
+func decodeGroup(name string, oldGroup *OptimizationGroup, newData any) (output *OptimizationGroup, err error) {
+	newDataMap, mapErr := asMap(newData, name)
+	if mapErr != nil {
+		err = mapErr
+		return output, err
+	}
+	newVariablesMap, variablesErr := asMap(newDataMap["variables"], name+".variables")
+	if variablesErr != nil {
+		err = variablesErr
+		return output, err
+	}
+	output = &OptimizationGroup{
+		Name:      oldGroup.Name,
+		Variables: map[string]any{},
+	}
+	knownIds := map[string]bool{}
+	for variableId, oldVariable := range oldGroup.Variables {
+		groupVariableId := variableBase(oldVariable).Id
+		knownIds[groupVariableId] = true
+		newVariable, newVariableExists := newVariablesMap[groupVariableId]
+		if newVariableExists == false {
+			output.Variables[variableId] = cloneVariable(oldVariable)
+			continue
+		}
+		output.Variables[variableId], err = decodeVariable(groupVariableId, oldVariable, newVariable)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			return output, err
+		}
+	}
+	for groupVariableId := range newVariablesMap {
+		if knownIds[groupVariableId] == false {
+			err = fmt.Errorf("%s: unknown group variable in prepare response: %s", name, groupVariableId)
+			return output, err
+		}
+	}
+	return output, err
+}
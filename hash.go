@@ -0,0 +1,84 @@
+package autocode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"hash"
+	"reflect"
+)
+
+func writeNodeHash(hash hash.Hash, node ast.Node) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		if node == nil {
+			hash.Write([]byte(")"))
+			return false
+		}
+		switch node.(type) {
+		case *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		reflectedNode := reflect.Indirect(reflect.ValueOf(node))
+		fmt.Fprintf(hash, "(%s", reflectedNode.Type().Name())
+		for index := 0; index < reflectedNode.NumField(); index++ {
+			field := reflectedNode.Field(index)
+			switch field.Type() {
+			case reflect.TypeOf(token.ILLEGAL):
+				fmt.Fprintf(hash, " %s", field.Interface().(token.Token))
+			case reflect.TypeOf(""):
+				fmt.Fprintf(hash, " %q", field.String())
+			case reflect.TypeOf(ast.SEND):
+				fmt.Fprintf(hash, " %d", field.Interface().(ast.ChanDir))
+			}
+		}
+		return true
+	})
+}
+
+// declaration returns the syntax tree of the function, parsed from the loaded source when the function is not
+// bound. Hash and NormalizedString share it so both see the same declaration.
+func (self *OptimizationFunctionValue) declaration() (output *ast.FuncDecl, err error) {
+	if self.isBound() == true || self.source == "" {
+		output, _, err = self.TryParse()
+		return output, err
+	}
+	file, parseErr := parser.ParseFile(token.NewFileSet(), "", "package source\n"+self.source, 0)
+	if parseErr != nil {
+		err = fmt.Errorf("function source not parseable: %s: %w", self.name, parseErr)
+		return output, err
+	}
+	for _, declaration := range file.Decls {
+		declaredFunction, isFunction := declaration.(*ast.FuncDecl)
+		if isFunction == true {
+			output = declaredFunction
+			return output, err
+		}
+	}
+	err = fmt.Errorf("%w: %s", ErrFunctionNotFound, self.name)
+	return output, err
+}
+
+// TryHash returns a SHA-256 of the function syntax tree, so it only changes when the code does and ignores
+// formatting, comments and the file the function lives in.
+func (self *OptimizationFunctionValue) TryHash() (output string, err error) {
+	functionDeclaration, declarationErr := self.declaration()
+	if declarationErr != nil {
+		err = declarationErr
+		return output, err
+	}
+	hash := sha256.New()
+	writeNodeHash(hash, functionDeclaration)
+	output = hex.EncodeToString(hash.Sum(nil))
+	return output, err
+}
+
+func (self *OptimizationFunctionValue) Hash() (output string) {
+	output, err := self.TryHash()
+	if err != nil {
+		panic(err)
+	}
+	return output
+}
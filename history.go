@@ -0,0 +1,130 @@
+package autocode
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type evaluationHistory struct {
+	records []EvaluationRecord
+	mutex   sync.Mutex
+}
+
+// EnableHistory starts recording every evaluation run by the client server so it can be exported later.
+// Records are kept in memory for the lifetime of the optimization.
+func (self *Optimization) EnableHistory() {
+	if self.history == nil {
+		self.history = &evaluationHistory{}
+	}
+}
+
+func (self *Optimization) History() (output []EvaluationRecord) {
+	if self.history == nil {
+		return output
+	}
+	self.history.mutex.Lock()
+	defer self.history.mutex.Unlock()
+	output = append([]EvaluationRecord{}, self.history.records...)
+	return output
+}
+
+func (self *Optimization) recordHistory(record EvaluationRecord) {
+	if self.history == nil {
+		return
+	}
+	self.history.mutex.Lock()
+	defer self.history.mutex.Unlock()
+	self.history.records = append(self.history.records, record)
+}
+
+func historyCell(variable any, value *OptimizationValue) (output string) {
+	if value == nil {
+		return output
+	}
+	_, isChoice := variable.(*OptimizationChoice)
+	if isChoice == true {
+		output = value.Id
+		return output
+	}
+	switch typedData := value.Data.(type) {
+	case float64:
+		output = strconv.FormatFloat(typedData, 'g', -1, 64)
+	case []byte:
+		output = base64.StdEncoding.EncodeToString(typedData)
+	default:
+		output = fmt.Sprint(typedData)
+	}
+	return output
+}
+
+// ExportHistoryCSV writes one row per recorded evaluation, with a column per variable holding its value, or the
+// selected option id for choices, followed by objective_<i>, inequality_constraint_<i> and
+// equality_constraint_<i> columns. Recording must be turned on with EnableHistory.
+func (self *Optimization) ExportHistoryCSV(writer io.Writer) (err error) {
+	if self.history == nil {
+		err = fmt.Errorf("history is not enabled")
+		return err
+	}
+	records := self.History()
+	variableIds := []string{}
+	for variableId := range self.Variables {
+		variableIds = append(variableIds, variableId)
+	}
+	sort.Strings(variableIds)
+	objectiveCount, inequalityCount, equalityCount := 0, 0, 0
+	for _, record := range records {
+		objectiveCount = max(objectiveCount, len(record.Response.Objectives))
+		inequalityCount = max(inequalityCount, len(record.Response.InequalityConstraints))
+		equalityCount = max(equalityCount, len(record.Response.EqualityConstraints))
+	}
+
+	header := append([]string{}, variableIds...)
+	for index := 0; index < objectiveCount; index++ {
+		header = append(header, fmt.Sprintf("objective_%d", index))
+	}
+	for index := 0; index < inequalityCount; index++ {
+		header = append(header, fmt.Sprintf("inequality_constraint_%d", index))
+	}
+	for index := 0; index < equalityCount; index++ {
+		header = append(header, fmt.Sprintf("equality_constraint_%d", index))
+	}
+	csvWriter := csv.NewWriter(writer)
+	err = csvWriter.Write(header)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := make([]string, 0, len(header))
+		for _, variableId := range variableIds {
+			row = append(row, historyCell(self.Variables[variableId], record.VariableValues[variableId]))
+		}
+		for _, columns := range []struct {
+			values []float64
+			count  int
+		}{
+			{record.Response.Objectives, objectiveCount},
+			{record.Response.InequalityConstraints, inequalityCount},
+			{record.Response.EqualityConstraints, equalityCount},
+		} {
+			for index := 0; index < columns.count; index++ {
+				cell := ""
+				if index < len(columns.values) {
+					cell = strconv.FormatFloat(columns.values[index], 'g', -1, 64)
+				}
+				row = append(row, cell)
+			}
+		}
+		err = csvWriter.Write(row)
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	err = csvWriter.Error()
+	return err
+}
@@ -0,0 +1,59 @@
+package autocode
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const MAX_ID_LENGTH = 128
+
+// Variable ids must be 1 to MAX_ID_LENGTH characters of ASCII letters, digits, '_', '-' or '.', so they stay
+// safe as map keys, inside generated option ids, in URL paths and on the wire.
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func ValidateId(id string) (err error) {
+	if id == "" {
+		err = fmt.Errorf("invalid id: must not be empty")
+		return err
+	}
+	if len(id) > MAX_ID_LENGTH {
+		err = fmt.Errorf("invalid id %q: longer than %d characters", id, MAX_ID_LENGTH)
+		return err
+	}
+	if idPattern.MatchString(id) == false {
+		err = fmt.Errorf("invalid id %q: only letters, digits, '_', '-' and '.' are allowed", id)
+		return err
+	}
+	return err
+}
+
+func mustValidateId(id string) {
+	err := ValidateId(id)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DEFAULT_OPTION_ID_SEPARATOR joins a choice id and an option index into the option ids generated by
+// NewOptimizationChoice, e.g. "optimizer_0".
+const DEFAULT_OPTION_ID_SEPARATOR = "_"
+
+// ValidateOptionIdSeparator accepts non-empty separators made of id characters other than digits, so the index
+// after the last separator can always be parsed back.
+func ValidateOptionIdSeparator(separator string) (err error) {
+	if idPattern.MatchString(separator) == false {
+		err = fmt.Errorf("invalid option id separator %q: must match %s", separator, idPattern.String())
+		return err
+	}
+	if strings.ContainsAny(separator, "0123456789") == true {
+		err = fmt.Errorf("invalid option id separator %q: must not contain digits", separator)
+		return err
+	}
+	return err
+}
+
+func newOptionId(choiceId string, separator string, index int) (output string) {
+	output = fmt.Sprintf("%s%s%d", choiceId, separator, index)
+	return output
+}
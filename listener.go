@@ -0,0 +1,59 @@
+package autocode
+
+import (
+	"context"
+	"net"
+)
+
+// ListenerOptions tunes the client server listener. The defaults match the Go runtime: SO_REUSEADDR on,
+// TCP_NODELAY on and a backlog of zero, meaning the system maximum (net.core.somaxconn on Linux). Tuning
+// mostly matters when the backend bursts many concurrent evaluate requests at the client server.
+type ListenerOptions struct {
+	ReuseAddress bool
+	Backlog      int
+	NoDelay      bool
+}
+
+func NewListenerOptions() *ListenerOptions {
+	return &ListenerOptions{
+		ReuseAddress: true,
+		Backlog:      0,
+		NoDelay:      true,
+	}
+}
+
+type noDelayListener struct {
+	net.Listener
+	noDelay bool
+}
+
+func (self *noDelayListener) Accept() (connection net.Conn, err error) {
+	connection, err = self.Listener.Accept()
+	if err != nil {
+		return connection, err
+	}
+	tcpConnection, isTcp := connection.(*net.TCPConn)
+	if isTcp == true {
+		err = tcpConnection.SetNoDelay(self.noDelay)
+	}
+	return connection, err
+}
+
+func (self *ListenerOptions) Listen(address string) (listener net.Listener, err error) {
+	if self.Backlog > 0 {
+		listener, err = listenWithBacklog(address, self.Backlog, self.ReuseAddress)
+	} else {
+		listenConfig := &net.ListenConfig{
+			Control: controlReuseAddress(self.ReuseAddress),
+		}
+		listener, err = listenConfig.Listen(context.Background(), "tcp4", address)
+	}
+	if err != nil {
+		return listener, err
+	}
+	listener = &noDelayListener{
+		Listener: listener,
+		noDelay:  self.NoDelay,
+	}
+	return listener, err
+}
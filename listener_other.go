@@ -0,0 +1,18 @@
+//go:build !unix
+
+package autocode
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+func controlReuseAddress(reuseAddress bool) func(network string, address string, connection syscall.RawConn) error {
+	return nil
+}
+
+func listenWithBacklog(address string, backlog int, reuseAddress bool) (listener net.Listener, err error) {
+	err = fmt.Errorf("listener backlog is not supported on this platform")
+	return listener, err
+}
@@ -0,0 +1,64 @@
+//go:build unix
+
+package autocode
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+func controlReuseAddress(reuseAddress bool) func(network string, address string, connection syscall.RawConn) error {
+	return func(network string, address string, connection syscall.RawConn) (err error) {
+		value := 0
+		if reuseAddress == true {
+			value = 1
+		}
+		controlErr := connection.Control(func(descriptor uintptr) {
+			err = syscall.SetsockoptInt(int(descriptor), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, value)
+		})
+		if controlErr != nil {
+			err = controlErr
+		}
+		return err
+	}
+}
+
+func listenWithBacklog(address string, backlog int, reuseAddress bool) (listener net.Listener, err error) {
+	tcpAddress, resolveErr := net.ResolveTCPAddr("tcp4", address)
+	if resolveErr != nil {
+		err = resolveErr
+		return listener, err
+	}
+	descriptor, socketErr := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, syscall.IPPROTO_TCP)
+	if socketErr != nil {
+		err = fmt.Errorf("failed to create socket: %w", socketErr)
+		return listener, err
+	}
+	file := os.NewFile(uintptr(descriptor), fmt.Sprintf("tcp:%s", address))
+	defer file.Close()
+
+	value := 0
+	if reuseAddress == true {
+		value = 1
+	}
+	err = syscall.SetsockoptInt(descriptor, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, value)
+	if err != nil {
+		return listener, err
+	}
+	socketAddress := &syscall.SockaddrInet4{Port: tcpAddress.Port}
+	copy(socketAddress.Addr[:], tcpAddress.IP.To4())
+	err = syscall.Bind(descriptor, socketAddress)
+	if err != nil {
+		err = fmt.Errorf("failed to bind %s: %w", address, err)
+		return listener, err
+	}
+	err = syscall.Listen(descriptor, backlog)
+	if err != nil {
+		err = fmt.Errorf("failed to listen on %s: %w", address, err)
+		return listener, err
+	}
+	listener, err = net.FileListener(file)
+	return listener, err
+}
@@ -0,0 +1,116 @@
+package autocode
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MASKED_OBJECTIVE_VALUE replaces masked objectives. Masked objectives keep their slot so the number of
+// objectives still matches what the backend was prepared with, but the backend sees a constant for them.
+const MASKED_OBJECTIVE_VALUE = 0.0
+
+// ConstraintDependency declares that the constraint at Index of the Kind slice is computed from the listed
+// objectives, so those objectives cannot be masked while the constraint is active.
+type ConstraintDependency struct {
+	Kind       string
+	Index      int
+	Objectives []int
+}
+
+func (self *Optimization) AddConstraintDependency(kind string, index int, objectiveIndexes ...int) (err error) {
+	if kind != RESPONSE_INEQUALITY && kind != RESPONSE_EQUALITY {
+		err = fmt.Errorf("unsupported constraint kind: %s", kind)
+		return err
+	}
+	if index < 0 {
+		err = fmt.Errorf("%s index must be non-negative: %d", kind, index)
+		return err
+	}
+	dependency := &ConstraintDependency{
+		Kind:       kind,
+		Index:      index,
+		Objectives: append([]int{}, objectiveIndexes...),
+	}
+	err = self.checkDependency(dependency, self.MaskedObjectives, false)
+	if err != nil {
+		return err
+	}
+	self.ConstraintDependencies = append(self.ConstraintDependencies, dependency)
+	return err
+}
+
+// SetObjectiveMask masks the objectives at the given indexes: parallel objectives at those indexes are skipped
+// and the values returned by the evaluation are replaced with MASKED_OBJECTIVE_VALUE. Evaluate implementations
+// can skip the work with IsObjectiveMasked. Calling it without indexes clears the mask.
+func (self *Optimization) SetObjectiveMask(objectiveIndexes ...int) (err error) {
+	for _, objectiveIndex := range objectiveIndexes {
+		if objectiveIndex < 0 {
+			err = fmt.Errorf("objective index must be non-negative: %d", objectiveIndex)
+			return err
+		}
+		if self.Counts != nil && objectiveIndex >= self.Counts.NumObjectives {
+			err = fmt.Errorf("objective index %d is out of the %d declared objectives", objectiveIndex, self.Counts.NumObjectives)
+			return err
+		}
+	}
+	for _, dependency := range self.ConstraintDependencies {
+		err = self.checkDependency(dependency, objectiveIndexes, false)
+		if err != nil {
+			return err
+		}
+	}
+	self.MaskedObjectives = append([]int(nil), objectiveIndexes...)
+	return err
+}
+
+func (self *Optimization) IsObjectiveMasked(objectiveIndex int) (output bool) {
+	output = slices.Contains(self.MaskedObjectives, objectiveIndex)
+	return output
+}
+
+// checkDependency fails when the constraint uses a masked objective. Constraints with a conditional predicate
+// are only checked at evaluation time, when evaluated is true, and only while the predicate holds.
+func (self *Optimization) checkDependency(dependency *ConstraintDependency, maskedObjectives []int, evaluated bool) (err error) {
+	for _, condition := range self.ConstraintConditions {
+		if condition.Kind != dependency.Kind || condition.Index != dependency.Index {
+			continue
+		}
+		if evaluated == false || condition.Predicate(self) == false {
+			return err
+		}
+	}
+	for _, objectiveIndex := range dependency.Objectives {
+		if slices.Contains(maskedObjectives, objectiveIndex) == true {
+			err = fmt.Errorf("%s %d depends on masked objective %d", dependency.Kind, dependency.Index, objectiveIndex)
+			return err
+		}
+	}
+	return err
+}
+
+func (self *Optimization) applyObjectiveMask(response *OptimizationEvaluateRunResponse) (err error) {
+	if len(self.MaskedObjectives) == 0 {
+		return err
+	}
+	if response.Infeasible == false {
+		for _, dependency := range self.ConstraintDependencies {
+			err = self.checkDependency(dependency, self.MaskedObjectives, true)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	response.Objectives = slices.Clone(response.Objectives)
+	response.ObjectiveStdErr = slices.Clone(response.ObjectiveStdErr)
+	for _, objectiveIndex := range self.MaskedObjectives {
+		if objectiveIndex >= len(response.Objectives) {
+			err = fmt.Errorf("masked objective %d has no slot, evaluation returned %d", objectiveIndex, len(response.Objectives))
+			return err
+		}
+		response.Objectives[objectiveIndex] = MASKED_OBJECTIVE_VALUE
+		if objectiveIndex < len(response.ObjectiveStdErr) {
+			response.ObjectiveStdErr[objectiveIndex] = 0
+		}
+	}
+	return err
+}
@@ -0,0 +1,131 @@
+package autocode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+const METRIC_ERROR_POTENTIALITY = "error_potentiality"
+const METRIC_UNDERSTANDABILITY = "understandability"
+const METRIC_COMPLEXITY = "complexity"
+const METRIC_OVERALL_MAINTAINABILITY = "overall_maintainability"
+const METRIC_MODULARITY = "modularity"
+const METRIC_READABILITY = "readability"
+
+// MetricsProvider computes code metrics of a function option locally, keyed by the METRIC_* names. Metrics it
+// leaves out keep their current value.
+type MetricsProvider interface {
+	Compute(functionDeclaration *ast.FuncDecl, fileSet *token.FileSet) (map[string]float64, error)
+}
+
+// LineCountMetricsProvider is a reference MetricsProvider reporting the number of source lines of a function as
+// its complexity.
+type LineCountMetricsProvider struct{}
+
+func NewLineCountMetricsProvider() *LineCountMetricsProvider {
+	return &LineCountMetricsProvider{}
+}
+
+func (self *LineCountMetricsProvider) Compute(functionDeclaration *ast.FuncDecl, fileSet *token.FileSet) (output map[string]float64, err error) {
+	startLine := fileSet.Position(functionDeclaration.Pos()).Line
+	endLine := fileSet.Position(functionDeclaration.End()).Line
+	output = map[string]float64{
+		METRIC_COMPLEXITY: float64(endLine - startLine + 1),
+	}
+	return output, err
+}
+
+func (self *OptimizationFunctionValue) setMetric(metric string, value float64) (err error) {
+	switch metric {
+	case METRIC_ERROR_POTENTIALITY:
+		self.ErrorPotentiality = value
+	case METRIC_UNDERSTANDABILITY:
+		self.Understandability = value
+	case METRIC_COMPLEXITY:
+		self.Complexity = value
+	case METRIC_OVERALL_MAINTAINABILITY:
+		self.OverallMaintainability = value
+	case METRIC_MODULARITY:
+		self.Modularity = value
+	case METRIC_READABILITY:
+		self.Readability = value
+	default:
+		err = fmt.Errorf("unknown metric: %s", metric)
+	}
+	return err
+}
+
+func (self *Optimization) SetMetricsProvider(provider MetricsProvider) {
+	self.MetricsProvider = provider
+}
+
+// ComputeMetrics fills the metrics of every bound function option, including those in nested choices, with
+// the MetricsProvider. Prepare calls it after applying the backend response, so local metrics take precedence.
+func (self *Optimization) ComputeMetrics() (err error) {
+	if self.MetricsProvider == nil {
+		err = fmt.Errorf("metrics provider is not set")
+		return err
+	}
+	var computeChoice func(choice *OptimizationChoice) (err error)
+	computeChoice = func(choice *OptimizationChoice) (err error) {
+		for _, optionId := range choice.SortedOptionIds() {
+			option := choice.Options[optionId]
+			switch typedData := option.Data.(type) {
+			case *OptimizationChoice:
+				err = computeChoice(typedData)
+			case *OptimizationGroup:
+				for _, groupVariable := range typedData.Variables {
+					groupChoice, isGroupChoice := groupVariable.(*OptimizationChoice)
+					if isGroupChoice == true {
+						err = computeChoice(groupChoice)
+					}
+					if err != nil {
+						break
+					}
+				}
+			case *OptimizationFunctionValue:
+				if typedData.isBound() == false {
+					continue
+				}
+				err = self.computeFunctionMetrics(typedData)
+			}
+			if err != nil {
+				err = fmt.Errorf("failed to compute metrics of option %s of %s: %w", optionId, choice.Id, err)
+				return err
+			}
+		}
+		return err
+	}
+	for _, variable := range self.Variables {
+		choice, isChoice := variable.(*OptimizationChoice)
+		if isChoice == false {
+			continue
+		}
+		err = computeChoice(choice)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (self *Optimization) computeFunctionMetrics(function *OptimizationFunctionValue) (err error) {
+	functionDeclaration, fileSet, parseErr := function.TryParse()
+	if parseErr != nil {
+		err = parseErr
+		return err
+	}
+	metrics, computeErr := self.MetricsProvider.Compute(functionDeclaration, fileSet)
+	if computeErr != nil {
+		err = computeErr
+		return err
+	}
+	for metric, value := range metrics {
+		err = function.setMetric(metric, value)
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package autocode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const NAMESPACE_SEPARATOR = "."
+
+// VariableGroup builds variables whose ids share a namespace prefix, e.g. "model.lr" and "model.depth", so a
+// whole group can be read, pinned or decoded at once. Groups nest with Group.
+type VariableGroup struct {
+	Prefix string
+}
+
+func NewVariableGroup(prefix string) *VariableGroup {
+	mustValidateId(prefix)
+	return &VariableGroup{
+		Prefix: prefix,
+	}
+}
+
+func (self *VariableGroup) Id(name string) (output string) {
+	output = self.Prefix + NAMESPACE_SEPARATOR + name
+	return output
+}
+
+func (self *VariableGroup) Group(name string) *VariableGroup {
+	return NewVariableGroup(self.Id(name))
+}
+
+func (self *VariableGroup) Binary(name string, variableOptions ...VariableOption) *OptimizationBinary {
+	return NewOptimizationBinary(self.Id(name), variableOptions...)
+}
+
+func (self *VariableGroup) Integer(name string, lowerBound int64, upperBound int64, variableOptions ...VariableOption) *OptimizationInteger {
+	return NewOptimizationInteger(self.Id(name), lowerBound, upperBound, variableOptions...)
+}
+
+func (self *VariableGroup) IntegerSet(name string, values []int64, variableOptions ...VariableOption) *OptimizationInteger {
+	return NewOptimizationIntegerSet(self.Id(name), values, variableOptions...)
+}
+
+func (self *VariableGroup) Real(name string, lowerBound float64, upperBound float64, variableOptions ...VariableOption) *OptimizationReal {
+	return NewOptimizationReal(self.Id(name), lowerBound, upperBound, variableOptions...)
+}
+
+func (self *VariableGroup) Choice(name string, options []any, variableOptions ...VariableOption) *OptimizationChoice {
+	return NewOptimizationChoice(self.Id(name), options, variableOptions...)
+}
+
+// groupVariableIds returns the sorted ids of the variables directly or transitively inside the namespace.
+func (self *Optimization) groupVariableIds(prefix string) (output []string) {
+	namespace := prefix + NAMESPACE_SEPARATOR
+	for variableId := range self.Variables {
+		if strings.HasPrefix(variableId, namespace) == true {
+			output = append(output, variableId)
+		}
+	}
+	sort.Strings(output)
+	return output
+}
+
+// GetGroupValues resolves every variable of a namespace, keyed by its id without the prefix and separator.
+func (self *Optimization) GetGroupValues(prefix string) (output map[string]any, err error) {
+	variableIds := self.groupVariableIds(prefix)
+	if len(variableIds) == 0 {
+		err = fmt.Errorf("variable group is empty: %s", prefix)
+		return output, err
+	}
+	output = map[string]any{}
+	for _, variableId := range variableIds {
+		value, valueErr := self.getValueSafe(variableId)
+		if valueErr != nil {
+			err = valueErr
+			return output, err
+		}
+		output[strings.TrimPrefix(variableId, prefix+NAMESPACE_SEPARATOR)] = value
+	}
+	return output, err
+}
+
+// PinGroup pins the variables of a namespace, with values keyed by id without the prefix and separator. No
+// variable is pinned if any value is invalid.
+func (self *Optimization) PinGroup(prefix string, values map[string]any) (err error) {
+	pinnedValues := map[string]*OptimizationValue{}
+	for name, value := range values {
+		variableId := prefix + NAMESPACE_SEPARATOR + name
+		pinnedValue, pinnedValueErr := self.newVariableValue(variableId, value)
+		if pinnedValueErr != nil {
+			err = pinnedValueErr
+			return err
+		}
+		pinnedValues[variableId] = pinnedValue
+	}
+	if self.PinnedValues == nil {
+		self.PinnedValues = map[string]*OptimizationValue{}
+	}
+	for variableId, pinnedValue := range pinnedValues {
+		self.PinnedValues[variableId] = pinnedValue
+	}
+	self.resolvers = nil
+	return err
+}
+
+func (self *Optimization) UnpinGroup(prefix string) {
+	for _, variableId := range self.groupVariableIds(prefix) {
+		delete(self.PinnedValues, variableId)
+	}
+	self.resolvers = nil
+}
+
+// UnmarshalGroup fills the optimize-tagged fields of a struct like Unmarshal, reading the variable
+// prefix.FieldName for each field.
+func (self *Optimization) UnmarshalGroup(prefix string, into any) (err error) {
+	err = self.unmarshal(prefix+NAMESPACE_SEPARATOR, into)
+	return err
+}
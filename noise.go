@@ -0,0 +1,17 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// SetNoisy tells the backend that Evaluate is stochastic. The noise level is an optional estimate of the
+// standard deviation of the objectives; zero leaves it unspecified.
+func (self *Optimization) SetNoisy(noiseLevel float64) (err error) {
+	if noiseLevel < 0 {
+		err = fmt.Errorf("noise level must be non-negative: %v", noiseLevel)
+		return err
+	}
+	self.Noisy = true
+	self.NoiseLevel = noiseLevel
+	return err
+}
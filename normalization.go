@@ -0,0 +1,140 @@
+package autocode
+
+import (
+	"fmt"
+	"math"
+)
+
+type OptimizationScale struct {
+	Offset float64 `json:"offset"`
+	Scale  float64 `json:"scale"`
+}
+
+func NewReferenceScale(scale float64) *OptimizationScale {
+	return &OptimizationScale{
+		Offset: 0,
+		Scale:  scale,
+	}
+}
+
+func NewMinMaxScale(minimum float64, maximum float64) *OptimizationScale {
+	return &OptimizationScale{
+		Offset: minimum,
+		Scale:  maximum - minimum,
+	}
+}
+
+func (self *OptimizationScale) Normalize(value float64) (output float64) {
+	output = (value - self.Offset) / self.Scale
+	return output
+}
+
+func (self *OptimizationScale) Denormalize(value float64) (output float64) {
+	output = value*self.Scale + self.Offset
+	return output
+}
+
+func (self *OptimizationScale) NormalizeSpread(value float64) (output float64) {
+	output = value / math.Abs(self.Scale)
+	return output
+}
+
+func (self *OptimizationScale) DenormalizeSpread(value float64) (output float64) {
+	output = value * math.Abs(self.Scale)
+	return output
+}
+
+type OptimizationNormalization struct {
+	Objectives            []*OptimizationScale
+	InequalityConstraints []*OptimizationScale
+	EqualityConstraints   []*OptimizationScale
+}
+
+// Validate rejects zero and non-finite scales and offsets. Constraint scales must also be positive with no
+// offset, so that g <= 0 and h == 0 keep their meaning after normalization.
+func (self *OptimizationNormalization) Validate() (err error) {
+	groups := []struct {
+		name       string
+		scales     []*OptimizationScale
+		constraint bool
+	}{
+		{name: RESPONSE_OBJECTIVE, scales: self.Objectives, constraint: false},
+		{name: RESPONSE_INEQUALITY, scales: self.InequalityConstraints, constraint: true},
+		{name: RESPONSE_EQUALITY, scales: self.EqualityConstraints, constraint: true},
+	}
+	for _, group := range groups {
+		for index, scale := range group.scales {
+			if scale == nil {
+				continue
+			}
+			if math.IsNaN(scale.Scale) == true || math.IsInf(scale.Scale, 0) == true {
+				err = fmt.Errorf("%s scale must be finite: index %d: %v", group.name, index, scale.Scale)
+				return err
+			}
+			if math.IsNaN(scale.Offset) == true || math.IsInf(scale.Offset, 0) == true {
+				err = fmt.Errorf("%s offset must be finite: index %d: %v", group.name, index, scale.Offset)
+				return err
+			}
+			if scale.Scale == 0 {
+				err = fmt.Errorf("%s scale must be non-zero: index %d", group.name, index)
+				return err
+			}
+			if group.constraint == true && scale.Scale < 0 {
+				err = fmt.Errorf("%s scale must be positive: index %d: %v", group.name, index, scale.Scale)
+				return err
+			}
+			if group.constraint == true && scale.Offset != 0 {
+				err = fmt.Errorf("%s offset must be zero: index %d: %v", group.name, index, scale.Offset)
+				return err
+			}
+		}
+	}
+	return err
+}
+
+func applyScales(values []float64, scales []*OptimizationScale, transform func(*OptimizationScale, float64) float64) (output []float64) {
+	if values == nil {
+		return output
+	}
+	output = make([]float64, len(values))
+	for index, value := range values {
+		output[index] = value
+		if index < len(scales) && scales[index] != nil {
+			output[index] = transform(scales[index], value)
+		}
+	}
+	return output
+}
+
+func (self *OptimizationNormalization) Normalize(response *OptimizationEvaluateRunResponse) (output *OptimizationEvaluateRunResponse) {
+	output = &OptimizationEvaluateRunResponse{
+		Objectives:            applyScales(response.Objectives, self.Objectives, (*OptimizationScale).Normalize),
+		InequalityConstraints: applyScales(response.InequalityConstraints, self.InequalityConstraints, (*OptimizationScale).Normalize),
+		EqualityConstraints:   applyScales(response.EqualityConstraints, self.EqualityConstraints, (*OptimizationScale).Normalize),
+		ObjectiveStdErr:       applyScales(response.ObjectiveStdErr, self.Objectives, (*OptimizationScale).NormalizeSpread),
+		Metadata:              response.Metadata,
+		Infeasible:            response.Infeasible,
+	}
+	return output
+}
+
+func (self *OptimizationNormalization) Denormalize(response *OptimizationEvaluateRunResponse) (output *OptimizationEvaluateRunResponse) {
+	output = &OptimizationEvaluateRunResponse{
+		Objectives:            applyScales(response.Objectives, self.Objectives, (*OptimizationScale).Denormalize),
+		InequalityConstraints: applyScales(response.InequalityConstraints, self.InequalityConstraints, (*OptimizationScale).Denormalize),
+		EqualityConstraints:   applyScales(response.EqualityConstraints, self.EqualityConstraints, (*OptimizationScale).Denormalize),
+		ObjectiveStdErr:       applyScales(response.ObjectiveStdErr, self.Objectives, (*OptimizationScale).DenormalizeSpread),
+		Metadata:              response.Metadata,
+		Infeasible:            response.Infeasible,
+	}
+	return output
+}
+
+func (self *Optimization) SetNormalization(normalization *OptimizationNormalization) (err error) {
+	err = normalization.Validate()
+	if err != nil {
+		return err
+	}
+	self.Normalization = normalization
+	return err
+}
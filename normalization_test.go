@@ -0,0 +1,62 @@
+package autocode
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizationPreservesFeasibility(t *testing.T) {
+	normalization := &OptimizationNormalization{
+		Objectives:            []*OptimizationScale{NewMinMaxScale(10, 20)},
+		InequalityConstraints: []*OptimizationScale{NewReferenceScale(4), NewReferenceScale(0.5)},
+		EqualityConstraints:   []*OptimizationScale{NewReferenceScale(8)},
+	}
+	if err := normalization.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	response := &OptimizationEvaluateRunResponse{
+		Objectives:            []float64{15},
+		InequalityConstraints: []float64{-3, 2},
+		EqualityConstraints:   []float64{0},
+	}
+	output := normalization.Normalize(response)
+	for index, value := range response.InequalityConstraints {
+		if (value <= 0) != (output.InequalityConstraints[index] <= 0) {
+			t.Errorf("InequalityConstraints[%d] = %v, feasibility of %v changed", index, output.InequalityConstraints[index], value)
+		}
+	}
+	if output.EqualityConstraints[0] != 0 {
+		t.Errorf("EqualityConstraints[0] = %v, want 0", output.EqualityConstraints[0])
+	}
+}
+
+func TestNormalizationValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		normalization *OptimizationNormalization
+		valid         bool
+	}{
+		{name: "objective min max", normalization: &OptimizationNormalization{Objectives: []*OptimizationScale{NewMinMaxScale(1, 3)}}, valid: true},
+		{name: "objective negative scale", normalization: &OptimizationNormalization{Objectives: []*OptimizationScale{NewReferenceScale(-2)}}, valid: true},
+		{name: "objective zero scale", normalization: &OptimizationNormalization{Objectives: []*OptimizationScale{NewReferenceScale(0)}}, valid: false},
+		{name: "objective nan scale", normalization: &OptimizationNormalization{Objectives: []*OptimizationScale{NewReferenceScale(math.NaN())}}, valid: false},
+		{name: "objective infinite offset", normalization: &OptimizationNormalization{Objectives: []*OptimizationScale{{Offset: math.Inf(1), Scale: 1}}}, valid: false},
+		{name: "inequality reference", normalization: &OptimizationNormalization{InequalityConstraints: []*OptimizationScale{nil, NewReferenceScale(2)}}, valid: true},
+		{name: "inequality min max", normalization: &OptimizationNormalization{InequalityConstraints: []*OptimizationScale{NewMinMaxScale(1, 3)}}, valid: false},
+		{name: "inequality negative scale", normalization: &OptimizationNormalization{InequalityConstraints: []*OptimizationScale{NewReferenceScale(-2)}}, valid: false},
+		{name: "equality infinite scale", normalization: &OptimizationNormalization{EqualityConstraints: []*OptimizationScale{NewReferenceScale(math.Inf(1))}}, valid: false},
+		{name: "equality offset", normalization: &OptimizationNormalization{EqualityConstraints: []*OptimizationScale{{Offset: 1, Scale: 1}}}, valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			optimization := &Optimization{}
+			err := optimization.SetNormalization(test.normalization)
+			if (err == nil) != test.valid {
+				t.Fatalf("SetNormalization() error = %v, want valid %t", err, test.valid)
+			}
+			if test.valid == false && optimization.Normalization != nil {
+				t.Errorf("SetNormalization() stored an invalid normalization")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package autocode
+
+import (
+	"sync"
+)
+
+const DEFAULT_OBSERVER_BUFFER_SIZE = 64
+
+type EvaluationRecord struct {
+	VariableValues map[string]*OptimizationValue
+	Response       *OptimizationEvaluateRunResponse
+	TrialId        string
+}
+
+type evaluationObserver struct {
+	channel      chan EvaluationRecord
+	dropWhenFull bool
+}
+
+type evaluationObservers struct {
+	observers []*evaluationObserver
+	mutex     sync.Mutex
+}
+
+func (self *Optimization) Observe() <-chan EvaluationRecord {
+	return self.ObserveWithOptions(DEFAULT_OBSERVER_BUFFER_SIZE, true)
+}
+
+func (self *Optimization) ObserveWithOptions(bufferSize int, dropWhenFull bool) <-chan EvaluationRecord {
+	observer := &evaluationObserver{
+		channel:      make(chan EvaluationRecord, bufferSize),
+		dropWhenFull: dropWhenFull,
+	}
+	self.observers.mutex.Lock()
+	defer self.observers.mutex.Unlock()
+	self.observers.observers = append(self.observers.observers, observer)
+	return observer.channel
+}
+
+func (self *Optimization) notifyObservers(response *OptimizationEvaluateRunResponse) {
+	self.observers.mutex.Lock()
+	defer self.observers.mutex.Unlock()
+	if len(self.observers.observers) == 0 && self.history == nil {
+		return
+	}
+	variableValues := map[string]*OptimizationValue{}
+	for variableId, value := range self.VariableValues {
+		variableValues[variableId] = value
+	}
+	record := EvaluationRecord{
+		VariableValues: variableValues,
+		Response:       response,
+		TrialId:        self.EvaluationTrialId,
+	}
+	self.recordHistory(record)
+	for _, observer := range self.observers.observers {
+		if observer.dropWhenFull == false {
+			observer.channel <- record
+			continue
+		}
+		select {
+		case observer.channel <- record:
+		default:
+		}
+	}
+}
+
+func (self *Optimization) CloseObservers() {
+	self.observers.mutex.Lock()
+	defer self.observers.mutex.Unlock()
+	for _, observer := range self.observers.observers {
+		close(observer.channel)
+	}
+	self.observers.observers = nil
+}
@@ -1,20 +1,30 @@
 package autocode
 
 import (
-	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/valyala/fasthttp"
-	"github.com/valyala/fasthttp/fasthttpadaptor"
 	"go/ast"
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"net"
 	"net/http"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const VARIABLE_BINARY = "OptimizationBinary"
@@ -25,90 +35,209 @@ const VALUE_FUNCTION = "OptimizationValueFunction"
 const VALUE_BOOLEAN = "bool"
 const VALUE_INTEGER = "int"
 const VALUE_FLOAT = "float"
+const VALUE_BYTES = "bytes"
+const VALUE_APPLICATION = "OptimizationValueApplication"
+const VALUE_GROUP = "OptimizationGroup"
+const LANGUAGE_GO = "go"
+const LANGUAGE_PYTHON = "python"
+const LANGUAGE_JAVASCRIPT = "javascript"
+const LANGUAGE_TYPESCRIPT = "typescript"
+const LANGUAGE_JAVA = "java"
+const LANGUAGE_RUST = "rust"
+const DEFAULT_MAX_REQUEST_BODY_SIZE int64 = 10 * 1024 * 1024
+const DEFAULT_CHOICE_OPTIONS_SOFT_LIMIT = 1000
 
 type OptimizationVariable struct {
-	Id   string `json:"id"`
-	Type string `json:"type"`
+	Id          string            `json:"id"`
+	Type        string            `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+type VariableOption = func(variable *OptimizationVariable)
+
+func WithDescription(description string) VariableOption {
+	return func(variable *OptimizationVariable) {
+		variable.Description = description
+	}
+}
+
+// WithMetadata attaches free-form descriptive entries such as units or display hints to a variable. They are
+// carried to the backend untouched and do not affect the optimization.
+func WithMetadata(metadata map[string]string) VariableOption {
+	return func(variable *OptimizationVariable) {
+		if variable.Metadata == nil {
+			variable.Metadata = map[string]string{}
+		}
+		for key, value := range metadata {
+			variable.Metadata[key] = value
+		}
+	}
+}
+
+// baseMap returns the entries shared by every variable type: the id, the type and the optional description and
+// metadata.
+func (self *OptimizationVariable) baseMap() (output map[string]any) {
+	output = map[string]any{}
+	output["id"] = self.Id
+	output["type"] = self.Type
+	if self.Description != "" {
+		output["description"] = self.Description
+	}
+	if len(self.Metadata) > 0 {
+		output["metadata"] = self.Metadata
+	}
+	return output
+}
+
+func newOptimizationVariable(id string, variableType string, variableOptions []VariableOption) *OptimizationVariable {
+	variable := &OptimizationVariable{
+		Id:   id,
+		Type: variableType,
+	}
+	for _, variableOption := range variableOptions {
+		variableOption(variable)
+	}
+	return variable
 }
 
 type OptimizationBinary struct {
 	*OptimizationVariable
 }
 
-func NewOptimizationBinary(id string) *OptimizationBinary {
+func NewOptimizationBinary(id string, variableOptions ...VariableOption) *OptimizationBinary {
+	mustValidateId(id)
 	return &OptimizationBinary{
-		OptimizationVariable: &OptimizationVariable{
-			Id:   id,
-			Type: VARIABLE_BINARY,
-		},
+		OptimizationVariable: newOptimizationVariable(id, VARIABLE_BINARY, variableOptions),
 	}
 }
 
 func (self *OptimizationBinary) Map() (output map[string]any) {
-	data := map[string]any{}
-	data["id"] = self.Id
-	data["type"] = self.Type
+	data := self.baseMap()
 	output = data
 	return output
 }
 
 type OptimizationInteger struct {
 	*OptimizationVariable
+	// Bounds holds the lower bound at index 0 and the upper bound at index 1, both inclusive. Use
+	// NewOptimizationIntegerRange for exclusive ends.
 	Bounds [2]int64 `json:"bounds"`
+	// Values optionally restricts the variable to an explicit set of integers within Bounds.
+	Values []int64 `json:"values,omitempty"`
+}
+
+func (self *OptimizationInteger) LowerBound() (output int64) {
+	output = self.Bounds[0]
+	return output
+}
+
+func (self *OptimizationInteger) UpperBound() (output int64) {
+	output = self.Bounds[1]
+	return output
+}
+
+func (self *OptimizationInteger) Range() (output int64) {
+	output = self.Bounds[1] - self.Bounds[0]
+	return output
 }
 
 func (self *OptimizationInteger) Map() (output map[string]any) {
-	data := map[string]any{}
-	data["id"] = self.Id
-	data["type"] = self.Type
+	data := self.baseMap()
 	data["bounds"] = self.Bounds
+	data[INCLUSIVE_BOUNDS_KEY] = true
+	if len(self.Values) > 0 {
+		data["values"] = self.Values
+	}
 	output = data
 	return output
 }
 
-func NewOptimizationInteger(id string, lowerBound int64, upperBound int64) *OptimizationInteger {
+func NewOptimizationInteger(id string, lowerBound int64, upperBound int64, variableOptions ...VariableOption) *OptimizationInteger {
+	mustValidateId(id)
 	return &OptimizationInteger{
-		OptimizationVariable: &OptimizationVariable{
-			Id:   id,
-			Type: VARIABLE_INTEGER,
-		},
-		Bounds: [2]int64{lowerBound, upperBound},
+		OptimizationVariable: newOptimizationVariable(id, VARIABLE_INTEGER, variableOptions),
+		Bounds:               [2]int64{lowerBound, upperBound},
 	}
 }
 
 type OptimizationReal struct {
 	*OptimizationVariable
+	// Bounds holds the lower bound at index 0 and the upper bound at index 1.
 	Bounds [2]float64 `json:"bounds"`
 }
 
+func (self *OptimizationReal) LowerBound() (output float64) {
+	output = self.Bounds[0]
+	return output
+}
+
+func (self *OptimizationReal) UpperBound() (output float64) {
+	output = self.Bounds[1]
+	return output
+}
+
+func (self *OptimizationReal) Range() (output float64) {
+	output = self.Bounds[1] - self.Bounds[0]
+	return output
+}
+
 func (self *OptimizationReal) Map() (output map[string]any) {
-	data := map[string]any{}
-	data["id"] = self.Id
-	data["type"] = self.Type
+	data := self.baseMap()
 	data["bounds"] = self.Bounds
 	output = data
 	return output
 }
 
-func NewOptimizationReal(id string, lowerBound float64, upperBound float64) *OptimizationReal {
+func NewOptimizationIntegerSet(id string, values []int64, variableOptions ...VariableOption) *OptimizationInteger {
+	mustValidateId(id)
+	if len(values) == 0 {
+		panic(fmt.Errorf("integer set is empty: %s", id))
+	}
+	seenValues := map[int64]bool{}
+	lowerBound := values[0]
+	upperBound := values[0]
+	for _, value := range values {
+		if seenValues[value] == true {
+			panic(fmt.Errorf("integer set has duplicate value %d: %s", value, id))
+		}
+		seenValues[value] = true
+		lowerBound = min(lowerBound, value)
+		upperBound = max(upperBound, value)
+	}
+	return &OptimizationInteger{
+		OptimizationVariable: newOptimizationVariable(id, VARIABLE_INTEGER, variableOptions),
+		Bounds:               [2]int64{lowerBound, upperBound},
+		Values:               append([]int64{}, values...),
+	}
+}
+
+func NewOptimizationReal(id string, lowerBound float64, upperBound float64, variableOptions ...VariableOption) *OptimizationReal {
+	mustValidateId(id)
 	return &OptimizationReal{
-		OptimizationVariable: &OptimizationVariable{
-			Id:   id,
-			Type: VARIABLE_REAL,
-		},
-		Bounds: [2]float64{lowerBound, upperBound},
+		OptimizationVariable: newOptimizationVariable(id, VARIABLE_REAL, variableOptions),
+		Bounds:               [2]float64{lowerBound, upperBound},
 	}
 }
 
 type OptimizationChoice struct {
 	*OptimizationVariable
-	Options map[string]*OptimizationValue `json:"options"`
+	Options           map[string]*OptimizationValue `json:"options"`
+	optionIdSeparator string
+}
+
+// OptionIdSeparator returns the separator between the choice id and the index in generated option ids.
+// Choices that were not built by NewOptimizationChoiceSeparated use DEFAULT_OPTION_ID_SEPARATOR.
+func (self *OptimizationChoice) OptionIdSeparator() (output string) {
+	output = self.optionIdSeparator
+	if output == "" {
+		output = DEFAULT_OPTION_ID_SEPARATOR
+	}
+	return output
 }
 
 func (self *OptimizationChoice) Map() (output map[string]any) {
-	data := map[string]any{}
-	data["id"] = self.Id
-	data["type"] = self.Type
+	data := self.baseMap()
 	options := map[string]any{}
 	data["options"] = options
 	for optionId, option := range self.Options {
@@ -135,42 +264,88 @@ func getType(value any) string {
 		return VALUE_FLOAT
 	case bool:
 		return VALUE_BOOLEAN
-	case FunctionValue:
+	case []byte:
+		return VALUE_BYTES
+	case FunctionValue, ContextFunctionValue, *OptimizationFunctionValue:
 		return VALUE_FUNCTION
+	case OptimizationApplication:
+		return VALUE_APPLICATION
+	case *OptimizationGroup:
+		return VALUE_GROUP
+	case Mapper, json.Marshaler:
+		return VALUE_CUSTOM
 	default:
 		panic("Unknown type")
 	}
 }
 
-func NewOptimizationChoice(id string, options []any) *OptimizationChoice {
+func newChoiceOption(optionId string, option any) *OptimizationValue {
+	optionType := getType(option)
+	if optionType == VALUE_FUNCTION {
+		option = newFunctionValue(option)
+	} else if optionType == VALUE_APPLICATION {
+		option = &OptimizationApplicationValue{
+			Application: option.(OptimizationApplication),
+		}
+	}
+	return &OptimizationValue{
+		Id:   optionId,
+		Type: optionType,
+		Data: option,
+	}
+}
+
+// NewOptimizationChoice accepts options of any mix of int64, float64, bool, []byte, FunctionValue,
+// ContextFunctionValue, *OptimizationFunctionValue, OptimizationApplication and nested *OptimizationChoice; each
+// option keeps its own value type on the wire.
+func NewOptimizationChoice(id string, options []any, variableOptions ...VariableOption) *OptimizationChoice {
+	return NewOptimizationChoiceSeparated(id, DEFAULT_OPTION_ID_SEPARATOR, options, variableOptions...)
+}
+
+// NewOptimizationChoiceSeparated is NewOptimizationChoice with option ids "<id><separator><index>", e.g. with
+// "-" so a choice id ending in "_3" keeps option ids that read unambiguously. The separator must not contain
+// digits.
+func NewOptimizationChoiceSeparated(id string, separator string, options []any, variableOptions ...VariableOption) *OptimizationChoice {
+	mustValidateId(id)
+	separatorErr := ValidateOptionIdSeparator(separator)
+	if separatorErr != nil {
+		panic(separatorErr)
+	}
 	transformedOptions := map[string]*OptimizationValue{}
 	for index, option := range options {
-		optionId := fmt.Sprintf("%s_%d", id, index)
-		optionType := getType(option)
-		if optionType == VALUE_FUNCTION {
-			option = &OptimizationFunctionValue{
-				Function:               option.(FunctionValue),
-				Complexity:             0,
-				ErrorPotentiality:      0,
-				Modularity:             0,
-				OverallMaintainability: 0,
-				Understandability:      0,
-			}
-		}
-		transformedOptions[optionId] = &OptimizationValue{
-			Id:   optionId,
-			Type: optionType,
-			Data: option,
-		}
+		optionId := newOptionId(id, separator, index)
+		transformedOptions[optionId] = newChoiceOption(optionId, option)
+	}
+	choice := &OptimizationChoice{
+		OptimizationVariable: newOptimizationVariable(id, VARIABLE_CHOICE, variableOptions),
+		Options:              transformedOptions,
+		optionIdSeparator:    separator,
+	}
+	bindGroups(choice)
+	return choice
+}
 
+// NewOptimizationChoiceNamed is NewOptimizationChoice with option ids chosen by the caller, e.g. "optimizer_sgd",
+// instead of the "<id>_<index>" scheme. Option ids must be valid ids and must not reuse the choice id.
+func NewOptimizationChoiceNamed(id string, options map[string]any, variableOptions ...VariableOption) *OptimizationChoice {
+	mustValidateId(id)
+	if len(options) == 0 {
+		panic(fmt.Errorf("choice has no options: %s", id))
+	}
+	transformedOptions := map[string]*OptimizationValue{}
+	for optionId, option := range options {
+		mustValidateId(optionId)
+		if optionId == id {
+			panic(fmt.Errorf("option id of %s must differ from the choice id", id))
+		}
+		transformedOptions[optionId] = newChoiceOption(optionId, option)
 	}
-	return &OptimizationChoice{
-		OptimizationVariable: &OptimizationVariable{
-			Id:   id,
-			Type: VARIABLE_CHOICE,
-		},
-		Options: transformedOptions,
+	choice := &OptimizationChoice{
+		OptimizationVariable: newOptimizationVariable(id, VARIABLE_CHOICE, variableOptions),
+		Options:              transformedOptions,
 	}
+	bindGroups(choice)
+	return choice
 }
 
 type OptimizationValue struct {
@@ -187,6 +362,16 @@ func (self *OptimizationValue) Map() (output map[string]any) {
 	if self.Data != nil {
 		if data["type"] == VALUE_FUNCTION {
 			data["data"] = (self.Data.(*OptimizationFunctionValue)).Map()
+		} else if data["type"] == VALUE_APPLICATION {
+			data["data"] = (self.Data.(*OptimizationApplicationValue)).Map()
+		} else if data["type"] == VARIABLE_CHOICE {
+			data["data"] = (self.Data.(*OptimizationChoice)).Map()
+		} else if data["type"] == VALUE_GROUP {
+			data["data"] = (self.Data.(*OptimizationGroup)).Map()
+		} else if data["type"] == VALUE_CUSTOM {
+			data["data"] = customOptionMap(self.Data)
+		} else if data["type"] == VALUE_BYTES {
+			data["data"] = base64.StdEncoding.EncodeToString(self.Data.([]byte))
 		}
 	}
 	output = data
@@ -196,91 +381,226 @@ func (self *OptimizationValue) Map() (output map[string]any) {
 type FunctionValue = func(*Optimization, ...any) any
 type OptimizationFunctionValue struct {
 	Function               FunctionValue
+	ContextFunction        ContextFunctionValue
+	Timeout                time.Duration
 	ErrorPotentiality      float64
 	Understandability      float64
 	Complexity             float64
 	OverallMaintainability float64
 	Modularity             float64
 	Readability            float64
+	stats                  functionStatsCounter
+	name                   string
+	source                 string
+	sourceOwner            *Optimization
 }
 
 func (self *OptimizationFunctionValue) GetName() (output string) {
-	output = runtime.FuncForPC(reflect.ValueOf(self.Function).Pointer()).Name()
+	if self.isBound() == false {
+		output = self.name
+		return output
+	}
+	output = runtime.FuncForPC(reflect.ValueOf(self.target()).Pointer()).Name()
 	return output
 }
 
 func (self *OptimizationFunctionValue) Parse() (functionDeclaration *ast.FuncDecl, fileSet *token.FileSet) {
+	functionDeclaration, fileSet, parseErr := self.TryParse()
+	if parseErr != nil {
+		panic(parseErr)
+	}
+	return functionDeclaration, fileSet
+}
+
+func (self *OptimizationFunctionValue) TryParse() (functionDeclaration *ast.FuncDecl, fileSet *token.FileSet, err error) {
 	fileSet = token.NewFileSet()
-	function := runtime.FuncForPC(reflect.ValueOf(self.Function).Pointer())
+	function := runtime.FuncForPC(reflect.ValueOf(self.target()).Pointer())
 	segments := strings.Split(function.Name(), ".")
 	functionName := segments[len(segments)-1]
 	fileName, line := function.FileLine(0)
-	if file, err := parser.ParseFile(fileSet, fileName, nil, 0); err == nil {
-		for _, declaration := range file.Decls {
-			f, ok := declaration.(*ast.FuncDecl)
-			if ok && f.Name.Name == functionName {
-				functionDeclaration = f
-				return functionDeclaration, fileSet
-			}
+	source, readErr := self.readSource(fileName)
+	if readErr != nil {
+		err = fmt.Errorf("function source not readable: %s at %s:%d: %w", functionName, fileName, line, readErr)
+		return functionDeclaration, fileSet, err
+	}
+	file, parseErr := parser.ParseFile(fileSet, fileName, source, 0)
+	if parseErr != nil {
+		err = fmt.Errorf("function not parseable: %s at %s:%d: %w", functionName, fileName, line, parseErr)
+		return functionDeclaration, fileSet, err
+	}
+	for _, declaration := range file.Decls {
+		f, ok := declaration.(*ast.FuncDecl)
+		if ok && f.Name.Name == functionName {
+			functionDeclaration = f
+			return functionDeclaration, fileSet, err
 		}
 	}
-	panic(fmt.Errorf("function not found: %s at %s:%d", functionName, fileName, line))
+	err = fmt.Errorf("%w: %s at %s:%d", ErrFunctionNotFound, functionName, fileName, line)
+	return functionDeclaration, fileSet, err
 }
 
 func (self *OptimizationFunctionValue) GetString() (output string) {
-	functionDeclaration, fileSet := self.Parse()
-	buffer := &bytes.Buffer{}
-	printErr := printer.Fprint(buffer, fileSet, functionDeclaration)
-	if printErr != nil {
-		panic(printErr)
+	output, err := self.TryGetString()
+	if err != nil {
+		panic(err)
 	}
-	output = buffer.String()
 	return output
 }
 
+func (self *OptimizationFunctionValue) TryGetString() (output string, err error) {
+	builder := &strings.Builder{}
+	err = self.WriteString(builder)
+	if err != nil {
+		return output, err
+	}
+	output = builder.String()
+	return output, err
+}
+
+// WriteString prints the function source to writer without building it in memory first.
+func (self *OptimizationFunctionValue) WriteString(writer io.Writer) (err error) {
+	if self.isBound() == false && self.source != "" {
+		_, err = io.WriteString(writer, self.source)
+		return err
+	}
+	functionDeclaration, fileSet, parseErr := self.TryParse()
+	if parseErr != nil {
+		err = parseErr
+		return err
+	}
+	err = printer.Fprint(writer, fileSet, functionDeclaration)
+	return err
+}
+
+// Map omits the hash of functions whose source cannot be parsed, such as closures, instead of failing.
 func (self *OptimizationFunctionValue) Map() (output map[string]any) {
 	data := map[string]any{}
 	data["name"] = self.GetName()
-	data["string"] = self.GetString()
+	data["string"] = &functionSource{function: self}
+	hash, hashErr := self.TryHash()
+	if hashErr == nil {
+		data["hash"] = hash
+	}
+	output = data
+	return output
+}
+
+type OptimizationApplicationValue struct {
+	Application OptimizationApplication
+	name        string
+}
+
+func (self *OptimizationApplicationValue) GetName() (output string) {
+	if self.Application == nil {
+		output = self.name
+		return output
+	}
+	output = reflect.TypeOf(self.Application).String()
+	return output
+}
+
+func (self *OptimizationApplicationValue) Map() (output map[string]any) {
+	data := map[string]any{}
+	data["name"] = self.GetName()
 	output = data
 	return output
 }
 
 type OptimizationEvaluateRunResponse struct {
-	Objectives            []float64 `json:"objectives"`
-	InequalityConstraints []float64 `json:"inequality_constraints"`
-	EqualityConstraints   []float64 `json:"equality_constraints"`
+	Objectives            []float64      `json:"objectives"`
+	InequalityConstraints []float64      `json:"inequality_constraints"`
+	EqualityConstraints   []float64      `json:"equality_constraints"`
+	Metadata              map[string]any `json:"metadata,omitempty"`
+	// ObjectiveStdErr optionally holds the standard error of each objective for noise-aware backends.
+	ObjectiveStdErr []float64 `json:"objective_std_err,omitempty"`
+	Infeasible      bool      `json:"-"`
 }
 
 type OptimizationApplication interface {
 	Evaluate(ctx *Optimization) *OptimizationEvaluateRunResponse
 }
 
+// GetValue resolves a variable once per candidate and caches the result. Parallel objectives may call it
+// concurrently; when they ask for the same unresolved variable at the same time, each resolves it and the first
+// stored result is returned to all of them. They do not wait for each other, since a function option being
+// resolved may itself wait for a FunctionConcurrency slot held by another objective.
 func (self *Optimization) GetValue(variableId string, arguments ...any) (output any) {
+	self.recordAccess(variableId)
+	self.valuesMutex.Lock()
 	executedValue, executedValueExists := self.ExecutedVariableValues[variableId]
+	resolver, resolverExists := self.resolvers[variableId]
+	self.valuesMutex.Unlock()
 	if executedValueExists == true {
 		return executedValue
 	}
-	value, valueExists := self.VariableValues[variableId]
-	if valueExists == false {
-		panic(fmt.Errorf("variable value not found: %s", variableId))
+	if resolverExists == true {
+		output = resolver.resolve(self, arguments...)
+	} else {
+		value, valueExists := self.selectedValue(variableId)
+		if valueExists == false {
+			panic(fmt.Errorf("variable value not found: %s", variableId))
+		}
+		variable, _ := self.findVariable(variableId)
+		output = self.resolveValue(variable, value, arguments...)
+	}
+	self.valuesMutex.Lock()
+	defer self.valuesMutex.Unlock()
+	executedValue, executedValueExists = self.ExecutedVariableValues[variableId]
+	if executedValueExists == true {
+		output = executedValue
+		return output
+	}
+	self.ExecutedVariableValues[variableId] = output
+	return output
+}
+
+func (self *Optimization) Seed() (output int64) {
+	output = self.EvaluationSeed
+	return output
+}
+
+func (self *Optimization) TrialId() (output string) {
+	output = self.EvaluationTrialId
+	return output
+}
+
+// ResolvedValues returns a copy of the values resolved by the last evaluation, keyed by variable id. The map is
+// copied but the values are not, so mutable results such as slices are still shared.
+func (self *Optimization) ResolvedValues() (output map[string]any) {
+	self.valuesMutex.Lock()
+	defer self.valuesMutex.Unlock()
+	output = make(map[string]any, len(self.ExecutedVariableValues))
+	for variableId, value := range self.ExecutedVariableValues {
+		output[variableId] = value
 	}
-	if value.Type == VALUE_FUNCTION {
-		variable := self.Variables[variableId]
+	return output
+}
+
+func (self *Optimization) resolveValue(variable any, value *OptimizationValue, arguments ...any) (output any) {
+	_, isBinary := variable.(*OptimizationBinary)
+	if isBinary == true {
+		output = toBool(value.Data)
+	} else if value.Type == VALUE_FUNCTION {
 		choice := variable.(*OptimizationChoice)
 		option := choice.Options[value.Id]
 		function := option.Data.(*OptimizationFunctionValue)
-		output = function.Function(self, arguments...)
-	} else if value.Type == VALUE_INTEGER {
-		output = int64(value.Data.(float64))
-	} else if value.Type == VALUE_FLOAT {
-		output = value.Data.(float64)
-	} else if value.Type == VALUE_BOOLEAN {
-		output = value.Data.(bool)
+		output = function.Invoke(self, arguments...)
+	} else if value.Type == VALUE_APPLICATION {
+		choice := variable.(*OptimizationChoice)
+		option := choice.Options[value.Id]
+		application := option.Data.(*OptimizationApplicationValue)
+		output = application.Application.Evaluate(self)
+	} else if value.Type == VALUE_GROUP {
+		choice := variable.(*OptimizationChoice)
+		option := choice.Options[value.Id]
+		output = self.resolveGroup(option.Data.(*OptimizationGroup), arguments...)
 	} else {
-		panic(fmt.Errorf("unsupported value type: %s", value.Type))
+		goValue, goErr := value.Go()
+		if goErr != nil {
+			panic(goErr)
+		}
+		output = goValue
 	}
-	self.ExecutedVariableValues[variableId] = output
 	return output
 }
 
@@ -293,6 +613,72 @@ type Optimization struct {
 	ClientPort             int64
 	VariableValues         map[string]*OptimizationValue
 	ExecutedVariableValues map[string]any
+	ProgressCallbacks      []ProgressCallback
+	ClientServer           *fasthttp.Server
+	clientServerMutex      sync.Mutex
+	clientServerStopped    bool
+	Cache                  *OptimizationCache
+	MaxRequestBodySize     int64
+	Normalization          *OptimizationNormalization
+	HttpClient             *http.Client
+	EvaluationSeed         int64
+	ExclusiveGroups        [][]string
+	Evaluator              OptimizationEvaluator
+	PinnedValues           map[string]*OptimizationValue
+	observers              evaluationObservers
+	RequestTimeout         time.Duration
+	RequestHeaders         http.Header
+	RetryCount             int
+	RetryBackoff           time.Duration
+	RetryNonIdempotent     bool
+	ParallelObjectives     []ParallelObjective
+	EvaluationTimeout      time.Duration
+	Penalty                *OptimizationPenalty
+	RawPrepareResponse     map[string]any
+	Language               string
+	SourceRoot             string
+	SourceFileSystem       fs.FS
+	ListenerOptions        *ListenerOptions
+	ClientListener         net.Listener
+	resolvers              map[string]*valueResolver
+	InstrumentFunctions    bool
+	Codec                  Codec
+	Codecs                 []Codec
+	Logger                 *log.Logger
+	ChoiceOptionsSoftLimit int
+	ChoiceOptionsHardLimit int
+	EqualityTolerances     []float64
+	ServeRetryCount        int
+	ServeRetryBackoff      time.Duration
+	Counts                 *OptimizationCounts
+	countsChecked          bool
+	FunctionConcurrency    int
+	functionSlots          *functionSlots
+	functionSlotsMutex     sync.Mutex
+	Conditions             map[string]*OptimizationCondition
+	history                *evaluationHistory
+	Noisy                  bool
+	NoiseLevel             float64
+	EvaluationTrialId      string
+	AllowedRemotes         []string
+	RunTimeout             time.Duration
+	RunDeadline            time.Time
+	ShutdownCallbacks      []ShutdownCallback
+	Baseline               map[string]*OptimizationValue
+	baselineObjectives     []float64
+	MetricsProvider        MetricsProvider
+	Repeats                int
+	ConstraintConditions   []*OptimizationConstraintCondition
+	Environment            *OptimizationEnvironment
+	UnusedWarningAfter     int
+	usage                  variableUsage
+	MaskedObjectives       []int
+	ConstraintDependencies []*ConstraintDependency
+	Routes                 []*OptimizationRoute
+	preparing              atomic.Bool
+	TimingObjective        *TimingObjective
+	PanicHandler           PanicHandler
+	valuesMutex            sync.Mutex
 }
 
 func NewOptimization(
@@ -305,24 +691,69 @@ func NewOptimization(
 	transformedVariables := map[string]any{}
 	for _, variable := range variables {
 		variableId := getFieldValue(variable, "Id").(string)
+		mustValidateId(variableId)
 		_, variableExists := transformedVariables[variableId]
 		if variableExists == true {
 			panic(fmt.Errorf("variable already exists: %s", variableId))
 		}
 		transformedVariables[variableId] = variable
 	}
+	idErr := checkGroupVariableIds(transformedVariables)
+	if idErr != nil {
+		panic(idErr)
+	}
 	optimization = &Optimization{
-		Variables:   transformedVariables,
-		Application: application,
-		ServerHost:  serverHost,
-		ServerPort:  serverPort,
-		ServerUrl:   fmt.Sprintf("http://%s:%d", serverHost, serverPort),
-		ClientPort:  clientPort,
+		Variables:              transformedVariables,
+		Application:            application,
+		ServerHost:             serverHost,
+		ServerPort:             serverPort,
+		ServerUrl:              fmt.Sprintf("http://%s:%d", serverHost, serverPort),
+		ClientPort:             clientPort,
+		MaxRequestBodySize:     DEFAULT_MAX_REQUEST_BODY_SIZE,
+		HttpClient:             NewHttpClient(),
+		Evaluator:              NewLocalEvaluator(),
+		Language:               LANGUAGE_GO,
+		ListenerOptions:        NewListenerOptions(),
+		Codec:                  NewJsonCodec(),
+		Logger:                 log.Default(),
+		ChoiceOptionsSoftLimit: DEFAULT_CHOICE_OPTIONS_SOFT_LIMIT,
 	}
+	optimization.bindSources(transformedVariables)
 
 	return optimization
 }
 
+func NewHttpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 100
+	transport.DisableKeepAlives = false
+	return &http.Client{
+		Transport: transport,
+		Timeout:   0,
+	}
+}
+
+var SUPPORTED_LANGUAGES = []string{
+	LANGUAGE_GO,
+	LANGUAGE_PYTHON,
+	LANGUAGE_JAVASCRIPT,
+	LANGUAGE_TYPESCRIPT,
+	LANGUAGE_JAVA,
+	LANGUAGE_RUST,
+}
+
+func (self *Optimization) SetLanguage(language string) (err error) {
+	for _, supportedLanguage := range SUPPORTED_LANGUAGES {
+		if language == supportedLanguage {
+			self.Language = language
+			return err
+		}
+	}
+	err = fmt.Errorf("unsupported language %q: expected one of %v", language, SUPPORTED_LANGUAGES)
+	return err
+}
+
 func getFieldValue(variable any, field string) (output any) {
 	reflectedVariable := reflect.Indirect(reflect.ValueOf(variable))
 	fieldValue := reflectedVariable.FieldByName(field)
@@ -330,191 +761,429 @@ func getFieldValue(variable any, field string) (output any) {
 	return output
 }
 
+// Prepare sends the search space to the backend and serves evaluations until the client server stops. It can
+// be called again afterwards, e.g. after AddVariable or RemoveVariable, and starts from a clean per-run state.
 func (self *Optimization) Prepare() {
+	if self.preparing.CompareAndSwap(false, true) == false {
+		panic(ErrPrepareRunning)
+	}
+	defer self.preparing.Store(false)
+	self.resetPreparedState()
 	requestBody := &OptimizationPrepareRequest{
-		Language:  "go",
-		Variables: self.Variables,
-		Port:      self.ClientPort,
+		Language:           self.Language,
+		Variables:          self.Variables,
+		Port:               self.ClientPort,
+		ExclusiveGroups:    self.ExclusiveGroups,
+		PinnedValues:       self.PinnedValues,
+		EqualityTolerances: self.EqualityTolerances,
+		OptimizationCounts: self.Counts,
+		Noisy:              self.Noisy,
+		NoiseLevel:         self.NoiseLevel,
 	}
 
-	requestBodyMap := requestBody.Map()
-	requestBodyJson, jsonErr := json.Marshal(requestBodyMap)
-	if jsonErr != nil {
-		panic(jsonErr)
-	}
-	bodyBuffer := bytes.NewBuffer(requestBodyJson)
-	client := &http.Client{
-		Timeout: 0,
-	}
-	url := fmt.Sprintf("%s/apis/optimizations/prepares", self.ServerUrl)
-	response, responseErr := client.Post(url, "application/json", bodyBuffer)
-	if responseErr != nil {
-		panic(responseErr)
-	}
+	self.warnChoiceOptions()
 
-	if response.StatusCode != 200 {
-		panic("Failed to prepare")
+	responseBody := map[string]any{}
+	requestErr := self.doStreamRequest(context.Background(), http.MethodPost, "/apis/optimizations/prepares", requestBody.Map(), &responseBody)
+	if requestErr != nil {
+		panic(fmt.Errorf("failed to prepare: %w", requestErr))
 	}
+	self.RawPrepareResponse = responseBody
 
-	responseBody := map[string]any{}
-	decodeErr := json.NewDecoder(response.Body).Decode(&responseBody)
-	if decodeErr != nil {
-		panic(decodeErr)
-	}
-
-	for variableId, newVariable := range responseBody["variables"].(map[string]any) {
-		newVariableType := newVariable.(map[string]any)["type"].(string)
-		if newVariableType == VARIABLE_CHOICE {
-			newOptions := map[string]*OptimizationValue{}
-			for optionId, newOption := range newVariable.(map[string]any)["options"].(map[string]any) {
-				newOptionType := newOption.(map[string]any)["type"].(string)
-				if newOptionType == VALUE_FUNCTION {
-					newOptionData := newOption.(map[string]any)["data"].(map[string]any)
-					oldVariable := self.Variables[variableId]
-					oldOptions := oldVariable.(*OptimizationChoice).Options
-					oldOptionData := oldOptions[optionId].Data.(*OptimizationFunctionValue)
-					newOptions[optionId] = &OptimizationValue{
-						Id:   optionId,
-						Type: newOptionType,
-						Data: &OptimizationFunctionValue{
-							Function:               oldOptionData.Function,
-							ErrorPotentiality:      newOptionData["error_potentiality"].(float64),
-							Complexity:             newOptionData["complexity"].(float64),
-							Modularity:             newOptionData["modularity"].(float64),
-							OverallMaintainability: newOptionData["overall_maintainability"].(float64),
-							Understandability:      newOptionData["understandability"].(float64),
-							Readability:            newOptionData["readability"].(float64),
-						},
-					}
-				} else if newOptionType == VALUE_INTEGER {
-					newOptionData := newOption.(map[string]any)["data"].(int64)
-					newOptions[optionId] = &OptimizationValue{
-						Id:   optionId,
-						Type: newOptionType,
-						Data: newOptionData,
-					}
-				} else if newOptionType == VALUE_FLOAT {
-					newOptionData := newOption.(map[string]any)["data"].(float64)
-					newOptions[optionId] = &OptimizationValue{
-						Id:   optionId,
-						Type: newOptionType,
-						Data: newOptionData,
-					}
-				} else if newOptionType == VALUE_BOOLEAN {
-					newOptionData := newOption.(map[string]any)["data"].(bool)
-					newOptions[optionId] = &OptimizationValue{
-						Id:   optionId,
-						Type: newOptionType,
-						Data: newOptionData,
-					}
-				} else {
-					panic(fmt.Errorf("unsupported newOption type: %s", newOptionType))
-				}
-			}
-			self.Variables[variableId] = &OptimizationChoice{
-				OptimizationVariable: &OptimizationVariable{
-					Id:   variableId,
-					Type: newVariableType,
-				},
-				Options: newOptions,
-			}
-		} else if newVariableType == VARIABLE_INTEGER {
-			self.Variables[variableId] = &OptimizationInteger{
-				OptimizationVariable: &OptimizationVariable{
-					Id:   variableId,
-					Type: newVariableType,
-				},
-				Bounds: [2]int64{
-					int64(newVariable.(map[string]any)["bounds"].([]any)[0].(float64)),
-					int64(newVariable.(map[string]any)["bounds"].([]any)[1].(float64)),
-				},
-			}
-		} else if newVariableType == VARIABLE_REAL {
-			self.Variables[variableId] = &OptimizationReal{
-				OptimizationVariable: &OptimizationVariable{
-					Id:   variableId,
-					Type: newVariableType,
-				},
-				Bounds: [2]float64{
-					newVariable.(map[string]any)["bounds"].([]any)[0].(float64),
-					newVariable.(map[string]any)["bounds"].([]any)[1].(float64),
-				},
-			}
-		} else if newVariableType == VARIABLE_BINARY {
-			self.Variables[variableId] = &OptimizationBinary{
-				OptimizationVariable: &OptimizationVariable{
-					Id:   variableId,
-					Type: newVariableType,
-				},
-			}
-		} else {
-			panic(fmt.Errorf("unsupported variable type: %s", newVariableType))
+	applyErr := self.ApplyPrepareResponse(responseBody)
+	if applyErr != nil {
+		panic(fmt.Errorf("failed to prepare: %w", applyErr))
+	}
+	if self.MetricsProvider != nil {
+		metricsErr := self.ComputeMetrics()
+		if metricsErr != nil {
+			panic(fmt.Errorf("failed to prepare: %w", metricsErr))
 		}
 	}
 
 	self.StartClientServer()
 }
 
-func (self *Optimization) StartClientServer() {
+func (self *Optimization) LastPrepareResponse() (output map[string]any) {
+	output = self.RawPrepareResponse
+	return output
+}
+
+func (self *Optimization) Addr() (output net.Addr) {
+	if self.ClientListener == nil {
+		return output
+	}
+	output = self.ClientListener.Addr()
+	return output
+}
+
+func (self *Optimization) Handler() http.Handler {
 	router := mux.NewRouter()
+	router.Use(self.recoverPanics)
 	apiRouter := router.PathPrefix("/apis").Subrouter()
-	apiRouter.HandleFunc("/optimizations/evaluates/prepares", self.EvaluatePrepare).Methods(http.MethodPost)
-	apiRouter.HandleFunc("/optimizations/evaluates/runs", self.EvaluateRun).Methods(http.MethodGet)
-	address := fmt.Sprintf("%s:%d", "0.0.0.0", self.ClientPort)
-	serverErr := fasthttp.ListenAndServe(address, fasthttpadaptor.NewFastHTTPHandler(router))
-	if serverErr != nil {
-		panic(serverErr)
+	evaluateRouter := apiRouter.PathPrefix("/optimizations/evaluates").Subrouter()
+	evaluateRouter.Use(self.allowRemotes)
+	evaluateRouter.HandleFunc("/prepares", self.EvaluatePrepare).Methods(http.MethodPost)
+	evaluateRouter.HandleFunc("/runs", self.EvaluateRun).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/optimizations/progresses", self.Progress).Methods(http.MethodPost)
+	for _, route := range self.Routes {
+		router.Handle(route.Path, self.allowRemotes(route.Handler)).Methods(route.Method)
+	}
+	return router
+}
+
+func (self *Optimization) StartClientServer() {
+	serveErr := self.SuperviseClientServer()
+	if serveErr != nil {
+		panic(serveErr)
 	}
 }
 
 func (self *Optimization) EvaluatePrepare(writer http.ResponseWriter, reader *http.Request) {
 	requestBody := &OptimizationEvaluatePrepareRequest{}
-	decodeErr := json.NewDecoder(reader.Body).Decode(requestBody)
+	status, decodeErr := self.decodeRequestBody(writer, reader, requestBody)
 	if decodeErr != nil {
-		panic(decodeErr)
+		http.Error(writer, decodeErr.Error(), status)
+		return
+	}
+	if requestBody.VariableValues == nil {
+		http.Error(writer, "variable values are missing", http.StatusBadRequest)
+		return
+	}
+	normalizeErr := self.normalizeCandidate(requestBody.VariableValues)
+	if normalizeErr != nil {
+		http.Error(writer, normalizeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	if requestBody.PinnedValues != nil {
+		normalizeErr = self.normalizeCandidate(requestBody.PinnedValues)
+		if normalizeErr != nil {
+			http.Error(writer, normalizeErr.Error(), http.StatusBadRequest)
+			return
+		}
+		self.PinnedValues = requestBody.PinnedValues
 	}
 
 	self.VariableValues = requestBody.VariableValues
 	self.ExecutedVariableValues = map[string]any{}
+	self.EvaluationSeed = requestBody.Seed
+	self.EvaluationTrialId = requestBody.TrialId
+	self.prepareResolvers()
+}
+
+func (self *Optimization) evaluateCandidateOnce(ctx context.Context) (evaluation *OptimizationEvaluateRunResponse, err error) {
+	evaluation, err = self.evaluateTimed()
+	if err != nil {
+		return evaluation, err
+	}
+	if evaluation == nil {
+		err = fmt.Errorf("evaluation returned no response")
+		return evaluation, err
+	}
+	if len(self.ParallelObjectives) > 0 && evaluation.Infeasible == false {
+		offset := len(evaluation.Objectives)
+		skip := func(index int) bool {
+			return self.IsObjectiveMasked(offset + index)
+		}
+		objectives, objectivesErr := self.evaluateParallelObjectives(ctx, skip)
+		if objectivesErr != nil {
+			err = objectivesErr
+			return evaluation, err
+		}
+		combinedEvaluation := *evaluation
+		combinedEvaluation.Objectives = append(slices.Clone(evaluation.Objectives), objectives...)
+		evaluation = &combinedEvaluation
+	}
+	return evaluation, err
+}
+
+var ErrNotPrepared = errors.New("evaluate prepare not received")
+
+func (self *Optimization) RunEvaluation(ctx context.Context) (evaluation *OptimizationEvaluateRunResponse, err error) {
+	if self.VariableValues == nil {
+		err = ErrNotPrepared
+		return evaluation, err
+	}
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			self.notifyPanic(recovered)
+			err = fmt.Errorf("evaluation failed: %v", recovered)
+		}
+	}()
+	evaluation, err = self.evaluateCandidate(ctx)
+	if err != nil {
+		return evaluation, err
+	}
+	// The evaluator may keep the response it returned, such as a memoized one, so the steps below work on a
+	// copy and clone the slices they overwrite.
+	copiedEvaluation := *evaluation
+	evaluation = &copiedEvaluation
+	self.recordEvaluation()
+	if len(evaluation.ObjectiveStdErr) > 0 && len(evaluation.ObjectiveStdErr) != len(evaluation.Objectives) {
+		err = fmt.Errorf("evaluation returned %d objective standard errors for %d objectives", len(evaluation.ObjectiveStdErr), len(evaluation.Objectives))
+		return evaluation, err
+	}
+	err = self.checkCounts(evaluation)
+	if err != nil {
+		return evaluation, err
+	}
+	err = self.applyConstraintConditions(evaluation)
+	if err != nil {
+		return evaluation, err
+	}
+	err = self.applyBaseline(ctx, evaluation)
+	if err != nil {
+		return evaluation, err
+	}
+	self.applyPenalty(evaluation)
+	err = self.applyObjectiveMask(evaluation)
+	if err != nil {
+		return evaluation, err
+	}
+	if self.Normalization != nil {
+		evaluation = self.Normalization.Normalize(evaluation)
+	}
+	return evaluation, err
 }
 
 func (self *Optimization) EvaluateRun(writer http.ResponseWriter, reader *http.Request) {
-	evaluation := self.Application.Evaluate(self)
+	evaluation, evaluationErr := self.RunEvaluation(reader.Context())
+	if errors.Is(evaluationErr, ErrNotPrepared) == true {
+		http.Error(writer, evaluationErr.Error(), http.StatusConflict)
+		return
+	}
+	if evaluationErr != nil {
+		self.Logger.Printf("evaluation of trial %q failed: %v", self.EvaluationTrialId, evaluationErr)
+		http.Error(writer, evaluationErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	if evaluation.Metadata != nil {
+		_, metadataErr := self.responseCodec(reader).Marshal(evaluation.Metadata)
+		if metadataErr != nil {
+			http.Error(writer, fmt.Sprintf("evaluation metadata is not serializable: %v", metadataErr), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	self.notifyObservers(evaluation)
 
-	encodeErr := json.NewEncoder(writer).Encode(evaluation)
+	encodeErr := self.encodeResponseBody(writer, reader, evaluation)
 	if encodeErr != nil {
-		panic(encodeErr)
+		http.Error(writer, encodeErr.Error(), http.StatusInternalServerError)
+		return
 	}
 }
 
+func toInt64(data any) (output int64) {
+	switch typedData := data.(type) {
+	case int64:
+		output = typedData
+	case float64:
+		output = floatToInt64(typedData)
+	case json.Number:
+		integerData, parseErr := typedData.Int64()
+		if parseErr != nil {
+			floatData, floatParseErr := typedData.Float64()
+			if floatParseErr != nil {
+				panic(parseErr)
+			}
+			integerData = floatToInt64(floatData)
+		}
+		output = integerData
+	default:
+		panic(fmt.Errorf("unsupported integer data: %T", data))
+	}
+	return output
+}
+
+// floatToInt64 panics on a fractional, non-finite or out of range float instead of truncating it.
+func floatToInt64(data float64) (output int64) {
+	if data != math.Trunc(data) {
+		panic(fmt.Errorf("expected an integer: %v", data))
+	}
+	if data < math.MinInt64 || data >= math.MaxInt64 {
+		panic(fmt.Errorf("integer out of range: %v", data))
+	}
+	output = int64(data)
+	return output
+}
+
+func toFloat64(data any) (output float64) {
+	switch typedData := data.(type) {
+	case float64:
+		output = typedData
+	case int64:
+		output = float64(typedData)
+	case json.Number:
+		floatData, parseErr := typedData.Float64()
+		if parseErr != nil {
+			panic(parseErr)
+		}
+		output = floatData
+	default:
+		panic(fmt.Errorf("unsupported float data: %T", data))
+	}
+	return output
+}
+
+func (self *OptimizationValue) NormalizeData() (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("invalid %s data %v: %v", self.Type, self.Data, recovered)
+		}
+	}()
+	switch self.Type {
+	case VALUE_INTEGER:
+		self.Data = toInt64(self.Data)
+	case VALUE_FLOAT:
+		self.Data = toFloat64(self.Data)
+	case VALUE_BOOLEAN:
+		booleanData, isBoolean := self.Data.(bool)
+		if isBoolean == false {
+			panic(fmt.Errorf("expected bool: %T", self.Data))
+		}
+		self.Data = booleanData
+	case VALUE_BYTES:
+		self.Data = decodeBytes(self.Data)
+	}
+	return err
+}
+
+// NormalizeBinary turns the value of a binary variable into a VALUE_BOOLEAN value. Binary values are sent as
+// booleans, but 0 and 1 integers or floats are accepted too.
+func (self *OptimizationValue) NormalizeBinary() (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("invalid binary data %v: %v", self.Data, recovered)
+		}
+	}()
+	self.Data = toBool(self.Data)
+	self.Type = VALUE_BOOLEAN
+	return err
+}
+
+func toBool(data any) (output bool) {
+	switch typedData := data.(type) {
+	case bool:
+		output = typedData
+	case float64:
+		if typedData != 0 && typedData != 1 {
+			panic(fmt.Errorf("expected 0 or 1: %v", typedData))
+		}
+		output = typedData == 1
+	case int64, json.Number:
+		integerData := toInt64(typedData)
+		if integerData != 0 && integerData != 1 {
+			panic(fmt.Errorf("expected 0 or 1: %d", integerData))
+		}
+		output = integerData == 1
+	default:
+		panic(fmt.Errorf("unsupported bool data: %T", data))
+	}
+	return output
+}
+
+func decodeBytes(data any) (output []byte) {
+	switch typedData := data.(type) {
+	case []byte:
+		output = typedData
+	case string:
+		decodedData, decodeErr := base64.StdEncoding.DecodeString(typedData)
+		if decodeErr != nil {
+			panic(decodeErr)
+		}
+		output = decodedData
+	default:
+		panic(fmt.Errorf("unsupported bytes data: %T", data))
+	}
+	return output
+}
+
+const MAX_ERROR_BODY_SIZE int64 = 4096
+
+func newResponseError(message string, response *http.Response) (err error) {
+	body, readErr := io.ReadAll(io.LimitReader(response.Body, MAX_ERROR_BODY_SIZE))
+	if readErr != nil {
+		err = fmt.Errorf("%s: status %d: %w", message, response.StatusCode, readErr)
+		return err
+	}
+	err = fmt.Errorf("%s: status %d: %s", message, response.StatusCode, strings.TrimSpace(string(body)))
+	return err
+}
+
+func isMaxBytesError(err error) (output bool) {
+	maxBytesErr := &http.MaxBytesError{}
+	output = errors.As(err, &maxBytesErr)
+	return output
+}
+
 type OptimizationPrepareRequest struct {
-	Language  string         `json:"language"`
-	Port      int64          `json:"port"`
-	Variables map[string]any `json:"variables"`
+	Language           string                        `json:"language"`
+	Port               int64                         `json:"port"`
+	Variables          map[string]any                `json:"variables"`
+	ExclusiveGroups    [][]string                    `json:"exclusive_groups"`
+	PinnedValues       map[string]*OptimizationValue `json:"pinned_values"`
+	EqualityTolerances []float64                     `json:"equality_tolerances"`
+	Noisy              bool                          `json:"noisy"`
+	NoiseLevel         float64                       `json:"noise_level,omitempty"`
+	*OptimizationCounts
+}
+
+func variableMap(variable any) (output map[string]any) {
+	variableType := getType(variable)
+	switch variableType {
+	case VARIABLE_BINARY:
+		output = variable.(*OptimizationBinary).Map()
+	case VARIABLE_INTEGER:
+		output = variable.(*OptimizationInteger).Map()
+	case VARIABLE_REAL:
+		output = variable.(*OptimizationReal).Map()
+	case VARIABLE_CHOICE:
+		output = variable.(*OptimizationChoice).Map()
+	default:
+		panic("Unknown type")
+	}
+	return output
 }
 
 func (self *OptimizationPrepareRequest) Map() map[string]any {
 	transformedVariables := map[string]any{}
 	for variableId, variable := range self.Variables {
-		variableType := getType(variable)
-		switch variableType {
-		case VARIABLE_BINARY:
-			transformedVariables[variableId] = variable.(*OptimizationBinary).Map()
-		case VARIABLE_INTEGER:
-			transformedVariables[variableId] = variable.(*OptimizationInteger).Map()
-		case VARIABLE_REAL:
-			transformedVariables[variableId] = variable.(*OptimizationReal).Map()
-		case VARIABLE_CHOICE:
-			transformedVariables[variableId] = variable.(*OptimizationChoice).Map()
-		default:
-			panic("Unknown type")
-		}
+		transformedVariables[variableId] = variableMap(variable)
+	}
+	exclusiveGroups := self.ExclusiveGroups
+	if exclusiveGroups == nil {
+		exclusiveGroups = [][]string{}
+	}
+	equalityTolerances := self.EqualityTolerances
+	if equalityTolerances == nil {
+		equalityTolerances = []float64{}
 	}
-	return map[string]any{
-		"language":  self.Language,
-		"variables": transformedVariables,
-		"port":      self.Port,
+	pinnedValues := map[string]any{}
+	for variableId, pinnedValue := range self.PinnedValues {
+		pinnedValues[variableId] = pinnedValue.Map()
 	}
+	output := map[string]any{
+		"language":            self.Language,
+		"variables":           transformedVariables,
+		"port":                self.Port,
+		"exclusive_groups":    exclusiveGroups,
+		"pinned_values":       pinnedValues,
+		"equality_tolerances": equalityTolerances,
+		"noisy":               self.Noisy,
+	}
+	if self.NoiseLevel > 0 {
+		output["noise_level"] = self.NoiseLevel
+	}
+	if self.OptimizationCounts != nil {
+		output["num_objectives"] = self.NumObjectives
+		output["num_inequality_constraints"] = self.NumInequalityConstraints
+		output["num_equality_constraints"] = self.NumEqualityConstraints
+	}
+	return output
 }
 
 type OptimizationPrepareResponse struct {
@@ -523,4 +1192,7 @@ type OptimizationPrepareResponse struct {
 
 type OptimizationEvaluatePrepareRequest struct {
 	VariableValues map[string]*OptimizationValue `json:"variable_values"`
+	PinnedValues   map[string]*OptimizationValue `json:"pinned_values,omitempty"`
+	Seed           int64                         `json:"seed,omitempty"`
+	TrialId        string                        `json:"trial_id,omitempty"`
 }
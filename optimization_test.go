@@ -0,0 +1,266 @@
+package autocode
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testApplication struct {
+	evaluate func(ctx *Optimization) *OptimizationEvaluateRunResponse
+}
+
+func (self *testApplication) Evaluate(ctx *Optimization) *OptimizationEvaluateRunResponse {
+	return self.evaluate(ctx)
+}
+
+func postEvaluatePrepare(optimization *Optimization, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/apis/optimizations/evaluates/prepares", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	optimization.EvaluatePrepare(recorder, request)
+	return recorder
+}
+
+func getEvaluateRun(optimization *Optimization) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/apis/optimizations/evaluates/runs", nil)
+	recorder := httptest.NewRecorder()
+	optimization.EvaluateRun(recorder, request)
+	return recorder
+}
+
+func TestEvaluatePrepareAndRun(t *testing.T) {
+	application := &testApplication{
+		evaluate: func(ctx *Optimization) *OptimizationEvaluateRunResponse {
+			return &OptimizationEvaluateRunResponse{
+				Objectives: []float64{ctx.GetValue("learning_rate").(float64) * 2},
+			}
+		},
+	}
+	optimization := NewOptimization([]any{
+		NewOptimizationReal("learning_rate", 0.0, 1.0),
+	}, application, "localhost", 0, 0)
+
+	prepareRecorder := postEvaluatePrepare(optimization, `{"variable_values": {"learning_rate": {"id": "learning_rate", "type": "float", "data": 0.25}}, "seed": 7, "trial_id": "trial-1"}`)
+	if prepareRecorder.Code != http.StatusOK {
+		t.Fatalf("EvaluatePrepare() status = %d, body = %q", prepareRecorder.Code, prepareRecorder.Body.String())
+	}
+	if optimization.Seed() != 7 || optimization.TrialId() != "trial-1" {
+		t.Errorf("seed, trial id = %d, %q, want 7, %q", optimization.Seed(), optimization.TrialId(), "trial-1")
+	}
+
+	runRecorder := getEvaluateRun(optimization)
+	if runRecorder.Code != http.StatusOK {
+		t.Fatalf("EvaluateRun() status = %d, body = %q", runRecorder.Code, runRecorder.Body.String())
+	}
+	response := &OptimizationEvaluateRunResponse{}
+	decodeErr := json.Unmarshal(runRecorder.Body.Bytes(), response)
+	if decodeErr != nil {
+		t.Fatalf("EvaluateRun() body is not JSON: %v", decodeErr)
+	}
+	if len(response.Objectives) != 1 || response.Objectives[0] != 0.5 {
+		t.Errorf("EvaluateRun() objectives = %v, want [0.5]", response.Objectives)
+	}
+}
+
+func TestEvaluatePrepareRejectsMalformedInput(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid json", body: `{"variable_values": `},
+		{name: "missing variable values", body: `{"seed": 1}`},
+		{name: "wrong shape", body: `{"variable_values": [1, 2]}`},
+		{name: "missing value", body: `{"variable_values": {"depth": null}}`},
+		{name: "fractional integer", body: `{"variable_values": {"depth": {"type": "int", "data": 1.5}}}`},
+		{name: "non binary number", body: `{"variable_values": {"enabled": {"type": "int", "data": 2}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			optimization := NewOptimization([]any{
+				NewOptimizationInteger("depth", 0, 10),
+				NewOptimizationBinary("enabled"),
+			}, nil, "localhost", 0, 0)
+			recorder := postEvaluatePrepare(optimization, test.body)
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("EvaluatePrepare() status = %d, want %d", recorder.Code, http.StatusBadRequest)
+			}
+			if optimization.VariableValues != nil {
+				t.Errorf("EvaluatePrepare() kept variable values of a rejected request: %v", optimization.VariableValues)
+			}
+		})
+	}
+}
+
+func TestEvaluateRunWithoutPrepare(t *testing.T) {
+	optimization := NewOptimization([]any{NewOptimizationBinary("enabled")}, nil, "localhost", 0, 0)
+	recorder := getEvaluateRun(optimization)
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("EvaluateRun() status = %d, want %d", recorder.Code, http.StatusConflict)
+	}
+}
+
+func TestEvaluateRunReportsPanics(t *testing.T) {
+	application := &testApplication{
+		evaluate: func(ctx *Optimization) *OptimizationEvaluateRunResponse {
+			panic("broken evaluation")
+		},
+	}
+	optimization := NewOptimization([]any{NewOptimizationBinary("enabled")}, application, "localhost", 0, 0)
+	optimization.Logger = log.New(io.Discard, "", 0)
+	prepareRecorder := postEvaluatePrepare(optimization, `{"variable_values": {"enabled": {"type": "bool", "data": true}}}`)
+	if prepareRecorder.Code != http.StatusOK {
+		t.Fatalf("EvaluatePrepare() status = %d, body = %q", prepareRecorder.Code, prepareRecorder.Body.String())
+	}
+	recorder := getEvaluateRun(optimization)
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("EvaluateRun() status = %d, want %d", recorder.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(recorder.Body.String(), "broken evaluation") == false {
+		t.Errorf("EvaluateRun() body = %q, want the panic message", recorder.Body.String())
+	}
+}
+
+func TestRunEvaluationKeepsSharedResponse(t *testing.T) {
+	shared := &OptimizationEvaluateRunResponse{Objectives: []float64{1}}
+	application := &testApplication{
+		evaluate: func(ctx *Optimization) *OptimizationEvaluateRunResponse {
+			return shared
+		},
+	}
+	optimization := NewOptimization([]any{NewOptimizationBinary("enabled")}, application, "localhost", 0, 0)
+	optimization.ParallelObjectives = []ParallelObjective{
+		func(ctx context.Context, optimization *Optimization) (float64, error) {
+			return 2, nil
+		},
+	}
+	prepareRecorder := postEvaluatePrepare(optimization, `{"variable_values": {"enabled": {"type": "bool", "data": true}}}`)
+	if prepareRecorder.Code != http.StatusOK {
+		t.Fatalf("EvaluatePrepare() status = %d, body = %q", prepareRecorder.Code, prepareRecorder.Body.String())
+	}
+	for run := 0; run < 2; run++ {
+		evaluation, err := optimization.RunEvaluation(context.Background())
+		if err != nil {
+			t.Fatalf("RunEvaluation() error = %v", err)
+		}
+		if reflect.DeepEqual(evaluation.Objectives, []float64{1, 2}) == false {
+			t.Errorf("run %d objectives = %v, want [1 2]", run, evaluation.Objectives)
+		}
+	}
+	if reflect.DeepEqual(shared.Objectives, []float64{1}) == false {
+		t.Errorf("shared objectives = %v, want the unchanged [1]", shared.Objectives)
+	}
+}
+
+func TestEvaluatePrepareResolvesIntegers(t *testing.T) {
+	optimization := NewOptimization([]any{
+		NewOptimizationInteger("depth", -9007199254740993, 9007199254740993),
+	}, nil, "localhost", 0, 0)
+	tests := []struct {
+		data string
+		want int64
+	}{
+		{data: "3", want: 3},
+		{data: "9007199254740993", want: 9007199254740993},
+		{data: "-9007199254740993", want: -9007199254740993},
+	}
+	for _, test := range tests {
+		recorder := postEvaluatePrepare(optimization, `{"variable_values": {"depth": {"id": "depth", "type": "int", "data": `+test.data+`}}}`)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("EvaluatePrepare(%s) status = %d, body = %q", test.data, recorder.Code, recorder.Body.String())
+		}
+		value, isInteger := optimization.GetValue("depth").(int64)
+		if isInteger == false {
+			t.Fatalf("GetValue(%s) = %T, want int64", test.data, optimization.GetValue("depth"))
+		}
+		if value != test.want {
+			t.Errorf("GetValue(%s) = %d, want %d", test.data, value, test.want)
+		}
+	}
+}
+
+func TestEvaluatePrepareResolvesBinaries(t *testing.T) {
+	optimization := NewOptimization([]any{NewOptimizationBinary("enabled")}, nil, "localhost", 0, 0)
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{value: `{"type": "bool", "data": true}`, want: true},
+		{value: `{"type": "bool", "data": false}`, want: false},
+		{value: `{"type": "int", "data": 1}`, want: true},
+		{value: `{"type": "int", "data": 0}`, want: false},
+		{value: `{"type": "float", "data": 1.0}`, want: true},
+	}
+	for _, test := range tests {
+		recorder := postEvaluatePrepare(optimization, `{"variable_values": {"enabled": `+test.value+`}}`)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("EvaluatePrepare(%s) status = %d, body = %q", test.value, recorder.Code, recorder.Body.String())
+		}
+		value, isBool := optimization.GetValue("enabled").(bool)
+		if isBool == false {
+			t.Fatalf("GetValue(%s) = %T, want bool", test.value, optimization.GetValue("enabled"))
+		}
+		if value != test.want {
+			t.Errorf("GetValue(%s) = %t, want %t", test.value, value, test.want)
+		}
+	}
+
+	recorder := postEvaluatePrepare(optimization, `{"variable_values": {"enabled": {"type": "int", "data": 2}}}`)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("EvaluatePrepare(2) status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func FuzzEvaluatePrepare(f *testing.F) {
+	f.Add(`{"variable_values": {"depth": {"id": "depth", "type": "int", "data": 3}}}`)
+	f.Add(`{"variable_values": {"enabled": {"type": "int", "data": 1}, "learning_rate": {"type": "float", "data": 0.5}}}`)
+	f.Add(`{"variable_values": {"width": {"id": "width_3", "type": "bytes", "data": "cmF3"}}, "seed": 7}`)
+	f.Add(`{"variable_values": {"optimizer": {"id": "optimizer_0", "type": "OptimizationGroup"}, "optimizer.sgd.nesterov": {"type": "bool", "data": true}}}`)
+	f.Add(`{"variable_values": {"depth": {"type": "int", "data": 1.5}}}`)
+	f.Add(`{"variable_values": {"depth": null}}`)
+	f.Add(`{"variable_values": []}`)
+	f.Fuzz(func(t *testing.T, body string) {
+		optimization := newDecodeTestOptimization()
+		recorder := postEvaluatePrepare(optimization, body)
+		if recorder.Code != http.StatusOK && recorder.Code != http.StatusBadRequest {
+			t.Errorf("EvaluatePrepare() status = %d, want %d or %d", recorder.Code, http.StatusOK, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestNormalizeIntegerData(t *testing.T) {
+	tests := []struct {
+		data  any
+		want  int64
+		valid bool
+	}{
+		{data: 3.0, want: 3, valid: true},
+		{data: -9223372036854775808.0, want: math.MinInt64, valid: true},
+		{data: json.Number("4"), want: 4, valid: true},
+		{data: json.Number("5.0"), want: 5, valid: true},
+		{data: 2.5, valid: false},
+		{data: 1e19, valid: false},
+		{data: 9223372036854775808.0, valid: false},
+		{data: math.Inf(-1), valid: false},
+		{data: math.NaN(), valid: false},
+		{data: json.Number("2.5"), valid: false},
+		{data: json.Number("1e19"), valid: false},
+	}
+	for _, test := range tests {
+		value := &OptimizationValue{Type: VALUE_INTEGER, Data: test.data}
+		err := value.NormalizeData()
+		if (err == nil) != test.valid {
+			t.Errorf("NormalizeData(%v) error = %v, want valid %t", test.data, err, test.valid)
+			continue
+		}
+		if test.valid == true && value.Data != test.want {
+			t.Errorf("NormalizeData(%v) = %v, want %d", test.data, value.Data, test.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package autocode
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+type PanicHandler = func(recovered any, stack []byte)
+
+// OnPanic sets a handler observing every panic recovered while serving: in HTTP handlers, evaluations, attempts
+// retried by RecoverApplication, function options with a Timeout and parallel objectives. Each panic is
+// reported once. It runs on the goroutine that panicked, before the panic is turned into an error
+// response, so it must be safe for concurrent use. Set it before serving starts.
+func (self *Optimization) OnPanic(handler PanicHandler) {
+	self.PanicHandler = handler
+}
+
+// notifiedPanic carries a panic that was already passed to the PanicHandler where it was first recovered, so the
+// sites recovering it again further up do not report it twice.
+type notifiedPanic struct {
+	value any
+}
+
+func (self *notifiedPanic) Error() string {
+	return fmt.Sprint(self.value)
+}
+
+func (self *notifiedPanic) Unwrap() (err error) {
+	err, _ = self.value.(error)
+	return err
+}
+
+// notifyPanic must be called from the deferred function that recovered, so the stack still shows where the
+// panic happened. A panicking handler is logged and otherwise ignored.
+func (self *Optimization) notifyPanic(recovered any) {
+	_, isNotified := recovered.(*notifiedPanic)
+	if self.PanicHandler == nil || isNotified == true {
+		return
+	}
+	stack := debug.Stack()
+	defer func() {
+		handlerRecovered := recover()
+		if handlerRecovered != nil {
+			self.Logger.Printf("panic handler panicked: %v", handlerRecovered)
+		}
+	}()
+	self.PanicHandler(recovered, stack)
+}
+
+// reportPanic notifies the handler of a panic recovered before it reaches the serving goroutine and returns it
+// marked as reported, ready to be raised again. A *FeasibilityError is a deliberate signal and is returned as is.
+func (self *Optimization) reportPanic(recovered any) (output any) {
+	output = recovered
+	_, isFeasibilityError := recovered.(*FeasibilityError)
+	_, isNotified := recovered.(*notifiedPanic)
+	if recovered == nil || isFeasibilityError == true || isNotified == true {
+		return output
+	}
+	self.notifyPanic(recovered)
+	output = &notifiedPanic{value: recovered}
+	return output
+}
+
+// asFeasibilityError unwraps a reported panic before checking for a *FeasibilityError.
+func asFeasibilityError(recovered any) (output *FeasibilityError, ok bool) {
+	notified, isNotified := recovered.(*notifiedPanic)
+	if isNotified == true {
+		recovered = notified.value
+	}
+	output, ok = recovered.(*FeasibilityError)
+	return output, ok
+}
+
+func (self *Optimization) recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, reader *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			self.notifyPanic(recovered)
+			self.Logger.Printf("%s %s panicked: %v", reader.Method, reader.URL.Path, recovered)
+			http.Error(writer, fmt.Sprintf("internal error: %v", recovered), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(writer, reader)
+	})
+}
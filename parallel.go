@@ -0,0 +1,69 @@
+package autocode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type ParallelObjective = func(ctx context.Context, optimization *Optimization) (float64, error)
+
+func (self *Optimization) RegisterParallelObjectives(objectives ...ParallelObjective) {
+	self.ParallelObjectives = append(self.ParallelObjectives, objectives...)
+}
+
+func (self *Optimization) EvaluateParallelObjectives(ctx context.Context) (output []float64, err error) {
+	output, err = self.evaluateParallelObjectives(ctx, nil)
+	return output, err
+}
+
+// evaluateParallelObjectives leaves the objectives for which skip returns true at zero without running them.
+func (self *Optimization) evaluateParallelObjectives(ctx context.Context, skip func(index int) bool) (output []float64, err error) {
+	if self.EvaluationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, self.EvaluationTimeout)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	output = make([]float64, len(self.ParallelObjectives))
+	errs := make([]error, len(self.ParallelObjectives))
+	waitGroup := sync.WaitGroup{}
+	for index, objective := range self.ParallelObjectives {
+		if skip != nil && skip(index) == true {
+			continue
+		}
+		waitGroup.Add(1)
+		go func(index int, objective ParallelObjective) {
+			defer waitGroup.Done()
+			defer func() {
+				recovered := recover()
+				if recovered != nil {
+					self.notifyPanic(recovered)
+					errs[index] = fmt.Errorf("objective %d panicked: %v", index, recovered)
+					cancel()
+				}
+			}()
+			value, objectiveErr := objective(ctx, self)
+			if objectiveErr != nil {
+				errs[index] = fmt.Errorf("objective %d failed: %w", index, objectiveErr)
+				cancel()
+				return
+			}
+			output[index] = value
+		}(index, objective)
+	}
+	waitGroup.Wait()
+
+	for _, objectiveErr := range errs {
+		if objectiveErr != nil {
+			err = objectiveErr
+			return output, err
+		}
+	}
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return output, err
+}
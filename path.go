@@ -0,0 +1,55 @@
+package autocode
+
+import (
+	"fmt"
+	"strings"
+)
+
+func (self *Optimization) GetValuePath(path string, arguments ...any) (output any, err error) {
+	segments := strings.Split(path, "/")
+	variables := self.Variables
+	for index, segment := range segments {
+		variable, variableExists := variables[segment]
+		if variableExists == false {
+			err = fmt.Errorf("variable not found at path %s: %s", path, segment)
+			return output, err
+		}
+		value, valueExists := self.selectedValue(segment)
+		if valueExists == false {
+			err = fmt.Errorf("variable value not found at path %s: %s", path, segment)
+			return output, err
+		}
+		if index == len(segments)-1 {
+			output, err = self.resolveValuePath(path, variable, value, arguments...)
+			return output, err
+		}
+		choice, isChoice := variable.(*OptimizationChoice)
+		if isChoice == false {
+			err = fmt.Errorf("variable at path %s is not a choice: %s", path, segment)
+			return output, err
+		}
+		option, optionExists := choice.Options[value.Id]
+		if optionExists == false {
+			err = fmt.Errorf("selected option not found at path %s: %s", path, value.Id)
+			return output, err
+		}
+		nestedChoice, isNestedChoice := option.Data.(*OptimizationChoice)
+		if isNestedChoice == false {
+			err = fmt.Errorf("selected option at path %s is not a nested choice: %s", path, value.Id)
+			return output, err
+		}
+		variables = map[string]any{nestedChoice.Id: nestedChoice}
+	}
+	return output, err
+}
+
+func (self *Optimization) resolveValuePath(path string, variable any, value *OptimizationValue, arguments ...any) (output any, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("failed to resolve value at path %s: %v", path, recovered)
+		}
+	}()
+	output = self.resolveValue(variable, value, arguments...)
+	return output, err
+}
@@ -0,0 +1,87 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+const OBJECTIVE_MINIMIZE = "minimize"
+const OBJECTIVE_MAXIMIZE = "maximize"
+
+type FeasibilityError struct {
+	Reason string
+}
+
+func (self *FeasibilityError) Error() string {
+	return fmt.Sprintf("infeasible candidate: %s", self.Reason)
+}
+
+type OptimizationPenalty struct {
+	Directions []string
+	Values     []float64
+}
+
+func (self *OptimizationPenalty) Validate() (err error) {
+	if len(self.Directions) != len(self.Values) {
+		err = fmt.Errorf("penalty has %d directions but %d values", len(self.Directions), len(self.Values))
+		return err
+	}
+	for index, direction := range self.Directions {
+		value := self.Values[index]
+		if direction == OBJECTIVE_MINIMIZE && value <= 0 {
+			err = fmt.Errorf("penalty of minimized objective %d must be positive: %v", index, value)
+			return err
+		}
+		if direction == OBJECTIVE_MAXIMIZE && value >= 0 {
+			err = fmt.Errorf("penalty of maximized objective %d must be negative: %v", index, value)
+			return err
+		}
+		if direction != OBJECTIVE_MINIMIZE && direction != OBJECTIVE_MAXIMIZE {
+			err = fmt.Errorf("unsupported objective direction %d: %s", index, direction)
+			return err
+		}
+	}
+	return err
+}
+
+func (self *Optimization) SetPenalty(directions []string, values []float64) (err error) {
+	penalty := &OptimizationPenalty{
+		Directions: directions,
+		Values:     values,
+	}
+	err = penalty.Validate()
+	if err != nil {
+		return err
+	}
+	self.Penalty = penalty
+	return err
+}
+
+func (self *Optimization) evaluatePenalized() (output *OptimizationEvaluateRunResponse) {
+	if self.Penalty == nil {
+		output = self.Evaluator.Evaluate(self)
+		return output
+	}
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+		_, isFeasibilityError := asFeasibilityError(recovered)
+		if isFeasibilityError == false {
+			panic(recovered)
+		}
+		output = &OptimizationEvaluateRunResponse{
+			Infeasible: true,
+		}
+	}()
+	output = self.Evaluator.Evaluate(self)
+	return output
+}
+
+func (self *Optimization) applyPenalty(response *OptimizationEvaluateRunResponse) {
+	if self.Penalty == nil || response.Infeasible == false {
+		return
+	}
+	response.Objectives = append([]float64{}, self.Penalty.Values...)
+	response.ObjectiveStdErr = nil
+}
@@ -0,0 +1,169 @@
+package autocode
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrPrepareRunning = errors.New("prepare is running")
+
+// resetPreparedState clears everything derived from a previous Prepare, so Prepare can run again after the
+// search space changed between phases of an adaptive optimization. Cached results and the recorded history
+// belong to the previous search space and are dropped too.
+func (self *Optimization) resetPreparedState() {
+	self.VariableValues = nil
+	self.ExecutedVariableValues = map[string]any{}
+	self.resolvers = nil
+	self.EvaluationSeed = 0
+	self.EvaluationTrialId = ""
+	self.RawPrepareResponse = nil
+	self.countsChecked = false
+	self.baselineObjectives = nil
+	if self.Cache != nil {
+		self.Cache = NewOptimizationCache(self.Cache.Capacity, self.Cache.EvictionPolicy)
+	}
+	if self.history != nil {
+		self.history = &evaluationHistory{}
+	}
+	self.clientServerMutex.Lock()
+	self.ClientServer = nil
+	self.clientServerStopped = false
+	self.clientServerMutex.Unlock()
+	self.ClientListener = nil
+	self.usage.mutex.Lock()
+	self.usage.accessed = nil
+	self.usage.evaluations = 0
+	self.usage.mutex.Unlock()
+}
+
+// checkWithVariable checks that the sub-variables of group options stay unique once variable is set.
+func (self *Optimization) checkWithVariable(variableId string, variable any) (err error) {
+	variables := map[string]any{}
+	for existingId, existingVariable := range self.Variables {
+		variables[existingId] = existingVariable
+	}
+	variables[variableId] = variable
+	err = checkGroupVariableIds(variables)
+	return err
+}
+
+func (self *Optimization) checkNotPreparing() (err error) {
+	if self.preparing.Load() == true {
+		err = fmt.Errorf("search space cannot change: %w", ErrPrepareRunning)
+	}
+	return err
+}
+
+// AddVariable adds a variable between phases. The next Prepare sends it to the backend. It fails while a
+// baseline is set, since the baseline has no value for the new variable; clear it and set it again after.
+func (self *Optimization) AddVariable(variable any) (err error) {
+	err = self.checkNotPreparing()
+	if err != nil {
+		return err
+	}
+	if self.Baseline != nil {
+		err = fmt.Errorf("variable cannot be added while a baseline is set")
+		return err
+	}
+	_, isDomainer := variable.(Domainer)
+	if isDomainer == false {
+		err = fmt.Errorf("unsupported variable type: %T", variable)
+		return err
+	}
+	variableId := variableBase(variable).Id
+	err = ValidateId(variableId)
+	if err != nil {
+		return err
+	}
+	_, variableExists := self.Variables[variableId]
+	if variableExists == true {
+		err = fmt.Errorf("variable already exists: %s", variableId)
+		return err
+	}
+	err = self.checkWithVariable(variableId, variable)
+	if err != nil {
+		return err
+	}
+	self.Variables[variableId] = variable
+	self.bindSources(map[string]any{variableId: variable})
+	return err
+}
+
+// ReplaceVariable swaps a variable for one with the same id and type, e.g. with narrowed bounds. A variable
+// cannot change its type in place; remove it and add the new one instead. Pinned and baseline values must still
+// be valid.
+func (self *Optimization) ReplaceVariable(variable any) (err error) {
+	err = self.checkNotPreparing()
+	if err != nil {
+		return err
+	}
+	_, isDomainer := variable.(Domainer)
+	if isDomainer == false {
+		err = fmt.Errorf("unsupported variable type: %T", variable)
+		return err
+	}
+	variableId := variableBase(variable).Id
+	oldVariable, variableExists := self.Variables[variableId]
+	if variableExists == false {
+		err = fmt.Errorf("variable not found: %s", variableId)
+		return err
+	}
+	if getType(oldVariable) != getType(variable) {
+		err = fmt.Errorf("variable %s cannot change type from %s to %s", variableId, getType(oldVariable), getType(variable))
+		return err
+	}
+	pinnedValue, isPinned := self.PinnedValues[variableId]
+	if isPinned == true {
+		pinErr := validateValue(variable, pinnedValue)
+		if pinErr != nil {
+			err = fmt.Errorf("pinned value of %s does not fit the replacement: %w", variableId, pinErr)
+			return err
+		}
+	}
+	baselineValue, hasBaselineValue := self.Baseline[variableId]
+	if hasBaselineValue == true {
+		baselineErr := validateValue(variable, baselineValue)
+		if baselineErr != nil {
+			err = fmt.Errorf("baseline value of %s does not fit the replacement: %w", variableId, baselineErr)
+			return err
+		}
+	}
+	err = self.checkWithVariable(variableId, variable)
+	if err != nil {
+		return err
+	}
+	self.Variables[variableId] = variable
+	self.bindSources(map[string]any{variableId: variable})
+	return err
+}
+
+// RemoveVariable removes a variable between phases. Its pin and baseline value are dropped; it fails while an
+// exclusive group or a condition still refers to the variable.
+func (self *Optimization) RemoveVariable(variableId string) (err error) {
+	err = self.checkNotPreparing()
+	if err != nil {
+		return err
+	}
+	_, variableExists := self.Variables[variableId]
+	if variableExists == false {
+		err = fmt.Errorf("variable not found: %s", variableId)
+		return err
+	}
+	for _, group := range self.ExclusiveGroups {
+		if slices.Contains(group, variableId) == true {
+			err = fmt.Errorf("variable %s is in exclusive group %v", variableId, group)
+			return err
+		}
+	}
+	for conditionedId, condition := range self.Conditions {
+		if conditionedId == variableId || condition.ParentId == variableId {
+			err = fmt.Errorf("variable %s is used by the condition of %s", variableId, conditionedId)
+			return err
+		}
+	}
+	delete(self.Variables, variableId)
+	delete(self.PinnedValues, variableId)
+	delete(self.Baseline, variableId)
+	return err
+}
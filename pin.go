@@ -0,0 +1,43 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+func (self *Optimization) newVariableValue(variableId string, value any) (output *OptimizationValue, err error) {
+	variable, variableExists := self.findVariable(variableId)
+	if variableExists == false {
+		err = fmt.Errorf("variable not found: %s", variableId)
+		return output, err
+	}
+	output, err = NewOptimizationValueFromGo(variable, value)
+	return output, err
+}
+
+func (self *Optimization) Pin(variableId string, value any) (err error) {
+	pinnedValue, pinnedValueErr := self.newVariableValue(variableId, value)
+	if pinnedValueErr != nil {
+		err = pinnedValueErr
+		return err
+	}
+	if self.PinnedValues == nil {
+		self.PinnedValues = map[string]*OptimizationValue{}
+	}
+	self.PinnedValues[variableId] = pinnedValue
+	self.resolvers = nil
+	return err
+}
+
+func (self *Optimization) Unpin(variableId string) {
+	delete(self.PinnedValues, variableId)
+	self.resolvers = nil
+}
+
+// selectedValue returns the value of a variable for the current evaluation, preferring its pin.
+func (self *Optimization) selectedValue(variableId string) (output *OptimizationValue, ok bool) {
+	output, ok = self.PinnedValues[variableId]
+	if ok == false {
+		output, ok = self.VariableValues[variableId]
+	}
+	return output, ok
+}
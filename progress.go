@@ -0,0 +1,56 @@
+package autocode
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type OptimizationProgressRequest struct {
+	Objectives     []float64                     `json:"objectives"`
+	VariableValues map[string]*OptimizationValue `json:"variable_values"`
+}
+
+func (self *OptimizationProgressRequest) Validate() (err error) {
+	if len(self.Objectives) == 0 {
+		err = fmt.Errorf("progress objectives are empty")
+		return err
+	}
+	if self.VariableValues == nil {
+		err = fmt.Errorf("progress variable values are missing")
+		return err
+	}
+	for variableId, value := range self.VariableValues {
+		if value == nil {
+			err = fmt.Errorf("progress variable value is missing: %s", variableId)
+			return err
+		}
+	}
+	return err
+}
+
+type ProgressCallback = func(best *OptimizationProgressRequest)
+
+func (self *Optimization) OnProgress(callback ProgressCallback) {
+	self.ProgressCallbacks = append(self.ProgressCallbacks, callback)
+}
+
+func (self *Optimization) Progress(writer http.ResponseWriter, reader *http.Request) {
+	requestBody := &OptimizationProgressRequest{}
+	status, decodeErr := self.decodeRequestBody(writer, reader, requestBody)
+	if decodeErr != nil {
+		self.Logger.Printf("malformed progress payload: %v", decodeErr)
+		http.Error(writer, decodeErr.Error(), status)
+		return
+	}
+
+	validateErr := requestBody.Validate()
+	if validateErr != nil {
+		self.Logger.Printf("malformed progress payload: %v", validateErr)
+		http.Error(writer, validateErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, callback := range self.ProgressCallbacks {
+		callback(requestBody)
+	}
+}
@@ -0,0 +1,51 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+type recoveringApplication struct {
+	application OptimizationApplication
+	retries     int
+}
+
+// RecoverApplication wraps an application so that a panicking Evaluate is retried up to retries more times,
+// each with freshly resolved values. A panic on the last attempt is raised again as a *FeasibilityError, which
+// a configured Penalty turns into an infeasible result and which otherwise fails the evaluation. A
+// *FeasibilityError raised by the application is passed on without retrying. Every other panic is passed to the
+// PanicHandler as it is recovered, including the retried ones.
+func RecoverApplication(application OptimizationApplication, retries int) OptimizationApplication {
+	return &recoveringApplication{
+		application: application,
+		retries:     max(retries, 0),
+	}
+}
+
+func (self *recoveringApplication) evaluateOnce(ctx *Optimization) (output *OptimizationEvaluateRunResponse, recovered any) {
+	defer func() {
+		recovered = ctx.reportPanic(recover())
+	}()
+	output = self.application.Evaluate(ctx)
+	return output, recovered
+}
+
+func (self *recoveringApplication) Evaluate(ctx *Optimization) (output *OptimizationEvaluateRunResponse) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			ctx.ExecutedVariableValues = map[string]any{}
+		}
+		response, recovered := self.evaluateOnce(ctx)
+		if recovered == nil {
+			output = response
+			return output
+		}
+		_, isFeasibilityError := asFeasibilityError(recovered)
+		if isFeasibilityError == true {
+			panic(recovered)
+		}
+		if attempt >= self.retries {
+			panic(&notifiedPanic{value: &FeasibilityError{Reason: fmt.Sprintf("evaluation panicked after %d attempts: %v", attempt+1, recovered)}})
+		}
+		ctx.Logger.Printf("evaluation of trial %q panicked on attempt %d, retrying: %v", ctx.EvaluationTrialId, attempt+1, recovered)
+	}
+}
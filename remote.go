@@ -0,0 +1,68 @@
+package autocode
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+func parseRemotes(remotes []string) (output []netip.Prefix, err error) {
+	for _, remote := range remotes {
+		if strings.Contains(remote, "/") == false {
+			address, addressErr := netip.ParseAddr(remote)
+			if addressErr != nil {
+				err = fmt.Errorf("invalid allowed remote %q: %w", remote, addressErr)
+				return output, err
+			}
+			output = append(output, netip.PrefixFrom(address, address.BitLen()))
+			continue
+		}
+		prefix, prefixErr := netip.ParsePrefix(remote)
+		if prefixErr != nil {
+			err = fmt.Errorf("invalid allowed remote %q: %w", remote, prefixErr)
+			return output, err
+		}
+		output = append(output, prefix.Masked())
+	}
+	return output, err
+}
+
+// SetAllowedRemotes restricts the evaluate endpoints to remotes within the given CIDRs or addresses. An empty
+// list allows every remote.
+func (self *Optimization) SetAllowedRemotes(remotes []string) (err error) {
+	_, err = parseRemotes(remotes)
+	if err != nil {
+		return err
+	}
+	self.AllowedRemotes = append([]string{}, remotes...)
+	return err
+}
+
+func (self *Optimization) allowRemotes(next http.Handler) http.Handler {
+	if len(self.AllowedRemotes) == 0 {
+		return next
+	}
+	prefixes, parseErr := parseRemotes(self.AllowedRemotes)
+	if parseErr != nil {
+		panic(parseErr)
+	}
+	return http.HandlerFunc(func(writer http.ResponseWriter, reader *http.Request) {
+		host, _, splitErr := net.SplitHostPort(reader.RemoteAddr)
+		if splitErr != nil {
+			host = reader.RemoteAddr
+		}
+		address, addressErr := netip.ParseAddr(host)
+		if addressErr == nil {
+			address = address.Unmap()
+			for _, prefix := range prefixes {
+				if prefix.Contains(address) == true {
+					next.ServeHTTP(writer, reader)
+					return
+				}
+			}
+		}
+		http.Error(writer, fmt.Sprintf("remote is not allowed: %s", host), http.StatusForbidden)
+	})
+}
@@ -0,0 +1,130 @@
+package autocode
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+const METADATA_REPEATS = "repeats"
+const METADATA_OBJECTIVE_VARIANCES = "objective_variances"
+
+// SetRepeats makes every evaluation run Evaluate repeats times on the same candidate and report the mean of the
+// objectives and constraints. The sample variance of each objective is added to the metadata under
+// METADATA_OBJECTIVE_VARIANCES and the standard error of its mean to ObjectiveStdErr. Each repeat sees its own
+// Seed, derived from the seed sent by the backend, so seeded evaluations do not repeat the same draw.
+func (self *Optimization) SetRepeats(repeats int) (err error) {
+	if repeats < 1 {
+		err = fmt.Errorf("repeats must be at least 1: %d", repeats)
+		return err
+	}
+	self.Repeats = repeats
+	return err
+}
+
+func (self *Optimization) evaluateCandidate(ctx context.Context) (evaluation *OptimizationEvaluateRunResponse, err error) {
+	if self.Repeats <= 1 {
+		evaluation, err = self.evaluateCandidateOnce(ctx)
+		return evaluation, err
+	}
+	seed := self.EvaluationSeed
+	defer func() {
+		self.EvaluationSeed = seed
+	}()
+	evaluations := []*OptimizationEvaluateRunResponse{}
+	for repeat := 0; repeat < self.Repeats; repeat++ {
+		if repeat > 0 {
+			self.ExecutedVariableValues = map[string]any{}
+		}
+		self.EvaluationSeed = repeatSeed(seed, repeat)
+		repeatEvaluation, repeatErr := self.evaluateCandidateOnce(ctx)
+		if repeatErr != nil {
+			err = fmt.Errorf("repeat %d: %w", repeat, repeatErr)
+			return evaluation, err
+		}
+		if repeatEvaluation.Infeasible == true {
+			evaluation = repeatEvaluation
+			return evaluation, err
+		}
+		evaluations = append(evaluations, repeatEvaluation)
+	}
+	evaluation, err = averageEvaluations(evaluations)
+	return evaluation, err
+}
+
+// repeatSeed derives the seed of a repeat with a SplitMix64 step. The first repeat keeps the backend seed.
+func repeatSeed(seed int64, repeat int) (output int64) {
+	if repeat == 0 {
+		output = seed
+		return output
+	}
+	mixed := uint64(seed) + uint64(repeat)*0x9e3779b97f4a7c15
+	mixed = (mixed ^ (mixed >> 30)) * 0xbf58476d1ce4e5b9
+	mixed = (mixed ^ (mixed >> 27)) * 0x94d049bb133111eb
+	mixed = mixed ^ (mixed >> 31)
+	output = int64(mixed)
+	return output
+}
+
+func averageValues(name string, evaluations []*OptimizationEvaluateRunResponse, values func(evaluation *OptimizationEvaluateRunResponse) []float64) (means []float64, variances []float64, err error) {
+	count := len(values(evaluations[0]))
+	means = make([]float64, count)
+	variances = make([]float64, count)
+	for repeat, evaluation := range evaluations {
+		if len(values(evaluation)) != count {
+			err = fmt.Errorf("repeat %d returned %d %s, the first returned %d", repeat, len(values(evaluation)), name, count)
+			return means, variances, err
+		}
+		for index, value := range values(evaluation) {
+			means[index] += value / float64(len(evaluations))
+		}
+	}
+	for _, evaluation := range evaluations {
+		for index, value := range values(evaluation) {
+			variances[index] += (value - means[index]) * (value - means[index]) / float64(len(evaluations)-1)
+		}
+	}
+	return means, variances, err
+}
+
+func averageEvaluations(evaluations []*OptimizationEvaluateRunResponse) (output *OptimizationEvaluateRunResponse, err error) {
+	objectives, objectiveVariances, objectivesErr := averageValues("objectives", evaluations, func(evaluation *OptimizationEvaluateRunResponse) []float64 {
+		return evaluation.Objectives
+	})
+	if objectivesErr != nil {
+		err = objectivesErr
+		return output, err
+	}
+	inequalityConstraints, _, inequalityErr := averageValues("inequality constraints", evaluations, func(evaluation *OptimizationEvaluateRunResponse) []float64 {
+		return evaluation.InequalityConstraints
+	})
+	if inequalityErr != nil {
+		err = inequalityErr
+		return output, err
+	}
+	equalityConstraints, _, equalityErr := averageValues("equality constraints", evaluations, func(evaluation *OptimizationEvaluateRunResponse) []float64 {
+		return evaluation.EqualityConstraints
+	})
+	if equalityErr != nil {
+		err = equalityErr
+		return output, err
+	}
+	metadata := map[string]any{}
+	for key, value := range evaluations[len(evaluations)-1].Metadata {
+		metadata[key] = value
+	}
+	metadata[METADATA_REPEATS] = len(evaluations)
+	metadata[METADATA_OBJECTIVE_VARIANCES] = objectiveVariances
+	objectiveStdErr := make([]float64, len(objectiveVariances))
+	for index, variance := range objectiveVariances {
+		objectiveStdErr[index] = math.Sqrt(variance / float64(len(evaluations)))
+	}
+	output = &OptimizationEvaluateRunResponse{
+		Objectives:            objectives,
+		ObjectiveStdErr:       objectiveStdErr,
+		InequalityConstraints: inequalityConstraints,
+		EqualityConstraints:   equalityConstraints,
+		Metadata:              metadata,
+	}
+	return output, err
+}
@@ -0,0 +1,201 @@
+package autocode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const DEFAULT_RETRY_BACKOFF = 500 * time.Millisecond
+
+type retryableError struct {
+	err error
+	// sent is false only when the request cannot have reached the backend, e.g. when dialing failed.
+	sent bool
+}
+
+func (self *retryableError) Error() string {
+	return self.err.Error()
+}
+
+func (self *retryableError) Unwrap() error {
+	return self.err
+}
+
+func isDialError(err error) (output bool) {
+	opErr := &net.OpError{}
+	output = errors.As(err, &opErr) && opErr.Op == "dial"
+	return output
+}
+
+func isIdempotentMethod(method string) (output bool) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		output = true
+	}
+	return output
+}
+
+// requestBody opens a fresh body for every attempt. wait is nil for in-memory bodies and otherwise returns the
+// error of the encoder feeding the body.
+type requestBody func() (reader io.Reader, wait func() error)
+
+func bytesBody(body []byte) requestBody {
+	if body == nil {
+		return nil
+	}
+	return func() (reader io.Reader, wait func() error) {
+		reader = bytes.NewReader(body)
+		return reader, wait
+	}
+}
+
+// requestTarget is the server a request goes to, the backend unless e.g. a remote evaluator calls a worker.
+type requestTarget struct {
+	serverUrl  string
+	httpClient *http.Client
+}
+
+func (self *Optimization) backendTarget() (output requestTarget) {
+	output = requestTarget{
+		serverUrl:  self.ServerUrl,
+		httpClient: self.HttpClient,
+	}
+	return output
+}
+
+func (self *Optimization) doRequestOnce(ctx context.Context, target requestTarget, method string, path string, body requestBody, out any) (err error) {
+	if self.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, self.RequestTimeout)
+		defer cancel()
+	}
+	var bodyReader io.Reader
+	var wait func() error
+	if body != nil {
+		bodyReader, wait = body()
+	}
+	url := fmt.Sprintf("%s%s", target.serverUrl, path)
+	request, requestErr := http.NewRequestWithContext(ctx, method, url, bodyReader)
+	if requestErr != nil {
+		err = requestErr
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", self.Codec.ContentType())
+	}
+	request.Header.Set("Accept", self.Codec.ContentType())
+	for key, values := range self.RequestHeaders {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	response, responseErr := target.httpClient.Do(request)
+	if responseErr != nil {
+		if wait != nil {
+			encodeErr := wait()
+			if encodeErr != nil && errors.Is(encodeErr, io.ErrClosedPipe) == false {
+				err = fmt.Errorf("%s %s failed to encode the body: %w", method, path, encodeErr)
+				return err
+			}
+		}
+		err = &retryableError{err: responseErr, sent: isDialError(responseErr) == false}
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		err = newResponseError(fmt.Sprintf("%s %s failed", method, path), response)
+		if response.StatusCode >= 500 {
+			err = &retryableError{err: err, sent: true}
+		}
+		return err
+	}
+
+	if out != nil {
+		responseBody, readErr := io.ReadAll(response.Body)
+		if readErr != nil {
+			err = &retryableError{err: readErr, sent: true}
+			return err
+		}
+		decodeErr := self.Codec.Unmarshal(responseBody, out)
+		if decodeErr != nil {
+			err = fmt.Errorf("%s %s returned an undecodable body: %w", method, path, decodeErr)
+			return err
+		}
+	}
+	return err
+}
+
+func (self *Optimization) doRequest(ctx context.Context, method string, path string, body any, out any) (err error) {
+	err = self.doRequestTo(ctx, self.backendTarget(), method, path, body, out)
+	return err
+}
+
+func (self *Optimization) doRequestTo(ctx context.Context, target requestTarget, method string, path string, body any, out any) (err error) {
+	var bodyJson []byte
+	if body != nil {
+		marshaledBody, jsonErr := self.Codec.Marshal(body)
+		if jsonErr != nil {
+			err = jsonErr
+			return err
+		}
+		bodyJson = marshaledBody
+	}
+	err = self.doRequestBodyTo(ctx, target, method, path, bytesBody(bodyJson), out)
+	return err
+}
+
+// doStreamRequest encodes body while it is being sent when the codec supports it, and falls back to doRequest
+// otherwise. The body is re-encoded on every retry.
+func (self *Optimization) doStreamRequest(ctx context.Context, method string, path string, body any, out any) (err error) {
+	streamCodec, isStreamCodec := self.Codec.(StreamCodec)
+	if isStreamCodec == false {
+		err = self.doRequest(ctx, method, path, body, out)
+		return err
+	}
+	streamingBody := func() (reader io.Reader, wait func() error) {
+		reader, wait = streamBody(streamCodec, body)
+		return reader, wait
+	}
+	err = self.doRequestBody(ctx, method, path, streamingBody, out)
+	return err
+}
+
+// doRequestBody retries failed requests up to RetryCount times. A request with a non-idempotent method such as
+// POST is only retried when it cannot have reached the backend, unless RetryNonIdempotent is set, so that e.g. a
+// prepare or a tell is not applied twice.
+func (self *Optimization) doRequestBody(ctx context.Context, method string, path string, body requestBody, out any) (err error) {
+	err = self.doRequestBodyTo(ctx, self.backendTarget(), method, path, body, out)
+	return err
+}
+
+func (self *Optimization) doRequestBodyTo(ctx context.Context, target requestTarget, method string, path string, body requestBody, out any) (err error) {
+	retryBackoff := self.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = DEFAULT_RETRY_BACKOFF
+	}
+	for attempt := 0; ; attempt++ {
+		err = self.doRequestOnce(ctx, target, method, path, body, out)
+		retryErr, isRetryable := err.(*retryableError)
+		if isRetryable == false {
+			return err
+		}
+		unsafeRetry := retryErr.sent == true && isIdempotentMethod(method) == false && self.RetryNonIdempotent == false
+		if attempt >= self.RetryCount || unsafeRetry == true {
+			err = retryErr.err
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("%w: %w", ctx.Err(), retryErr.err)
+			return err
+		case <-time.After(retryBackoff * time.Duration(attempt+1)):
+		}
+	}
+}
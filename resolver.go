@@ -0,0 +1,58 @@
+package autocode
+
+// valueResolver holds the function or application of a selected option, looked up once per candidate. Plain
+// values need no resolver: GetValue converts them on first use and caches them in ExecutedVariableValues.
+type valueResolver struct {
+	function    *OptimizationFunctionValue
+	application OptimizationApplication
+}
+
+func (self *valueResolver) resolve(optimization *Optimization, arguments ...any) (output any) {
+	if self.function != nil {
+		output = self.function.Invoke(optimization, arguments...)
+	} else {
+		output = self.application.Evaluate(optimization)
+	}
+	return output
+}
+
+// newValueResolver returns nil when the value is not a function or application option of the variable. GetValue
+// then resolves it through resolveValue, which reports a mismatched value when it is used.
+func (self *Optimization) newValueResolver(variableId string, value *OptimizationValue) (output *valueResolver) {
+	if value.Type != VALUE_FUNCTION && value.Type != VALUE_APPLICATION {
+		return output
+	}
+	choice, isChoice := self.Variables[variableId].(*OptimizationChoice)
+	if isChoice == false {
+		return output
+	}
+	option, optionExists := choice.Options[value.Id]
+	if optionExists == false {
+		return output
+	}
+	switch typedData := option.Data.(type) {
+	case *OptimizationFunctionValue:
+		output = &valueResolver{function: typedData}
+	case *OptimizationApplicationValue:
+		output = &valueResolver{application: typedData.Application}
+	}
+	return output
+}
+
+func (self *Optimization) prepareResolvers() {
+	self.resolvers = map[string]*valueResolver{}
+	for variableId, value := range self.VariableValues {
+		resolver := self.newValueResolver(variableId, value)
+		if resolver != nil {
+			self.resolvers[variableId] = resolver
+		}
+	}
+	for variableId, value := range self.PinnedValues {
+		resolver := self.newValueResolver(variableId, value)
+		if resolver != nil {
+			self.resolvers[variableId] = resolver
+		} else {
+			delete(self.resolvers, variableId)
+		}
+	}
+}
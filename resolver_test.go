@@ -0,0 +1,59 @@
+package autocode
+
+import (
+	"testing"
+)
+
+func benchmarkFunction(optimization *Optimization, arguments ...any) any {
+	return len(arguments)
+}
+
+func newResolverBenchmarkOptimization() *Optimization {
+	optimization := NewOptimization([]any{
+		NewOptimizationBinary("binary"),
+		NewOptimizationInteger("integer", 0, 100),
+		NewOptimizationReal("real", 0.0, 1.0),
+		NewOptimizationChoice("choice", []any{int64(1), int64(2)}),
+		NewOptimizationChoice("function", []any{FunctionValue(benchmarkFunction)}),
+	}, nil, "localhost", 0, 0)
+	optimization.VariableValues = map[string]*OptimizationValue{
+		"binary":   {Id: "binary", Type: VALUE_BOOLEAN, Data: true},
+		"integer":  {Id: "integer", Type: VALUE_INTEGER, Data: int64(42)},
+		"real":     {Id: "real", Type: VALUE_FLOAT, Data: 0.5},
+		"choice":   {Id: "choice_1", Type: VALUE_INTEGER, Data: int64(2)},
+		"function": {Id: "function_0", Type: VALUE_FUNCTION},
+	}
+	return optimization
+}
+
+// BenchmarkGetValue resolves one variable per candidate, as an evaluation does after EvaluatePrepare.
+func BenchmarkGetValue(b *testing.B) {
+	for _, variableId := range []string{"binary", "integer", "real", "choice", "function"} {
+		b.Run(variableId, func(b *testing.B) {
+			optimization := newResolverBenchmarkOptimization()
+			b.ReportAllocs()
+			for index := 0; index < b.N; index++ {
+				optimization.ExecutedVariableValues = map[string]any{}
+				optimization.prepareResolvers()
+				optimization.GetValue(variableId)
+			}
+		})
+	}
+}
+
+// BenchmarkGetValueCached repeats the lookup of an already resolved variable within one candidate.
+func BenchmarkGetValueCached(b *testing.B) {
+	for _, variableId := range []string{"binary", "integer", "real", "choice", "function"} {
+		b.Run(variableId, func(b *testing.B) {
+			optimization := newResolverBenchmarkOptimization()
+			optimization.ExecutedVariableValues = map[string]any{}
+			optimization.prepareResolvers()
+			optimization.GetValue(variableId)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for index := 0; index < b.N; index++ {
+				optimization.GetValue(variableId)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package autocode
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const RESERVED_ROUTE_PREFIX = "/apis/optimizations"
+
+type OptimizationRoute struct {
+	Method  string
+	Path    string
+	Handler http.Handler
+}
+
+// AddRoute registers an extra handler, e.g. an admin or debug endpoint, on the client server. Routes are read
+// when Handler builds the router, so they must be added before serving starts. They are matched after the
+// built-in routes and are subject to the same allowed remotes as the evaluate endpoints.
+func (self *Optimization) AddRoute(method string, path string, handler http.Handler) (err error) {
+	if strings.HasPrefix(path, "/") == false {
+		err = fmt.Errorf("route path must start with a slash: %s", path)
+		return err
+	}
+	if path == RESERVED_ROUTE_PREFIX || strings.HasPrefix(path, RESERVED_ROUTE_PREFIX+"/") == true {
+		err = fmt.Errorf("route path is reserved: %s", path)
+		return err
+	}
+	if handler == nil {
+		err = fmt.Errorf("route %s %s needs a handler", method, path)
+		return err
+	}
+	for _, route := range self.Routes {
+		if route.Method == method && route.Path == path {
+			err = fmt.Errorf("route already exists: %s %s", method, path)
+			return err
+		}
+	}
+	self.Routes = append(self.Routes, &OptimizationRoute{
+		Method:  method,
+		Path:    path,
+		Handler: handler,
+	})
+	return err
+}
+
+func (self *Optimization) AddRouteFunc(method string, path string, handler http.HandlerFunc) (err error) {
+	err = self.AddRoute(method, path, handler)
+	return err
+}
@@ -0,0 +1,219 @@
+package autocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const SCHEMA_DRAFT = "https://json-schema.org/draft/2020-12/schema"
+
+func typeSchema(fieldType reflect.Type) (output map[string]any) {
+	switch fieldType.Kind() {
+	case reflect.Pointer:
+		output = typeSchema(fieldType.Elem())
+	case reflect.String:
+		output = map[string]any{"type": "string"}
+	case reflect.Bool:
+		output = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		output = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		output = map[string]any{"type": "number"}
+	case reflect.Array:
+		output = map[string]any{
+			"type":     "array",
+			"items":    typeSchema(fieldType.Elem()),
+			"minItems": fieldType.Len(),
+			"maxItems": fieldType.Len(),
+		}
+	case reflect.Slice:
+		output = map[string]any{
+			"type":  "array",
+			"items": typeSchema(fieldType.Elem()),
+		}
+	case reflect.Map:
+		output = map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(fieldType.Elem()),
+		}
+	case reflect.Struct:
+		output = structSchema(fieldType)
+	default:
+		output = map[string]any{}
+	}
+	return output
+}
+
+// structSchema follows encoding/json field rules: embedded structs are flattened, "-" and unexported fields
+// are skipped, and only fields without omitempty outside embedded pointers are required.
+func structSchema(structType reflect.Type) (output map[string]any) {
+	properties := map[string]any{}
+	required := []string{}
+	var walk func(structType reflect.Type, optional bool)
+	walk = func(structType reflect.Type, optional bool) {
+		for index := 0; index < structType.NumField(); index++ {
+			field := structType.Field(index)
+			if field.Anonymous == true {
+				embeddedType := field.Type
+				embeddedOptional := embeddedType.Kind() == reflect.Pointer
+				if embeddedOptional == true {
+					embeddedType = embeddedType.Elem()
+				}
+				walk(embeddedType, optional || embeddedOptional)
+				continue
+			}
+			if field.IsExported() == false {
+				continue
+			}
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, tagOptions, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = typeSchema(field.Type)
+			if optional == false && strings.Contains(tagOptions, "omitempty") == false {
+				required = append(required, name)
+			}
+		}
+	}
+	walk(structType, false)
+	output = map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+	return output
+}
+
+func variableSchema(variable any, variableType string) (output map[string]any) {
+	output = typeSchema(reflect.TypeOf(variable))
+	properties := output["properties"].(map[string]any)
+	properties["id"] = map[string]any{"$ref": "#/$defs/id"}
+	properties["type"] = map[string]any{"const": variableType}
+	return output
+}
+
+func valueDataSchema() (output map[string]any) {
+	nameSchema := map[string]any{"type": "string"}
+	output = map[string]any{
+		"anyOf": []any{
+			map[string]any{"type": "integer"},
+			map[string]any{"type": "number"},
+			map[string]any{"type": "boolean"},
+			map[string]any{"type": "string", "contentEncoding": "base64"},
+			map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{"name": nameSchema, "string": nameSchema, "hash": nameSchema},
+				"required":             []string{"name", "string", "hash"},
+				"additionalProperties": false,
+			},
+			map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{"name": nameSchema},
+				"required":             []string{"name"},
+				"additionalProperties": false,
+			},
+			map[string]any{"$ref": "#/$defs/choice"},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": nameSchema,
+					"variables": map[string]any{
+						"type":                 "object",
+						"additionalProperties": map[string]any{"$ref": "#/$defs/variable"},
+					},
+				},
+				"required":             []string{"name", "variables"},
+				"additionalProperties": false,
+			},
+			map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{"kind": nameSchema, "value": map[string]any{}},
+				"required":             []string{"kind", "value"},
+				"additionalProperties": false,
+			},
+		},
+	}
+	return output
+}
+
+// ExportSchema returns a JSON Schema of the prepare request, derived from the json tags of the request and
+// variable types. Bounds are [lower, upper] pairs; the ordering of the pair cannot be expressed in JSON Schema
+// and is only checked by Validate.
+func ExportSchema() (output []byte, err error) {
+	binarySchema := variableSchema(OptimizationBinary{}, VARIABLE_BINARY)
+	integerSchema := variableSchema(OptimizationInteger{}, VARIABLE_INTEGER)
+	integerSchema["properties"].(map[string]any)[INCLUSIVE_BOUNDS_KEY] = map[string]any{"const": true}
+	realSchema := variableSchema(OptimizationReal{}, VARIABLE_REAL)
+	choiceSchema := variableSchema(OptimizationChoice{}, VARIABLE_CHOICE)
+	choiceSchema["properties"].(map[string]any)["options"] = map[string]any{
+		"type":                 "object",
+		"minProperties":        1,
+		"additionalProperties": map[string]any{"$ref": "#/$defs/value"},
+	}
+
+	valueSchema := typeSchema(reflect.TypeOf(OptimizationValue{}))
+	valueProperties := valueSchema["properties"].(map[string]any)
+	valueProperties["type"] = map[string]any{
+		"enum": []string{VALUE_INTEGER, VALUE_FLOAT, VALUE_BOOLEAN, VALUE_BYTES, VALUE_FUNCTION, VALUE_APPLICATION, VARIABLE_CHOICE, VALUE_GROUP, VALUE_CUSTOM},
+	}
+	valueProperties["data"] = valueDataSchema()
+
+	requestSchema := typeSchema(reflect.TypeOf(OptimizationPrepareRequest{}))
+	requestProperties := requestSchema["properties"].(map[string]any)
+	requestProperties["language"] = map[string]any{"enum": SUPPORTED_LANGUAGES}
+	requestProperties["variables"] = map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"$ref": "#/$defs/variable"},
+	}
+	requestProperties["pinned_values"] = map[string]any{
+		"type":                 "object",
+		"additionalProperties": map[string]any{"$ref": "#/$defs/value"},
+	}
+	requestProperties["equality_tolerances"].(map[string]any)["items"] = map[string]any{
+		"type":    "number",
+		"minimum": 0,
+	}
+
+	schema := map[string]any{
+		"$schema": SCHEMA_DRAFT,
+		"title":   "OptimizationPrepareRequest",
+		"$defs": map[string]any{
+			"id": map[string]any{
+				"type":      "string",
+				"minLength": 1,
+				"maxLength": MAX_ID_LENGTH,
+				"pattern":   idPattern.String(),
+			},
+			"binary":  binarySchema,
+			"integer": integerSchema,
+			"real":    realSchema,
+			"choice":  choiceSchema,
+			"variable": map[string]any{
+				"oneOf": []any{
+					map[string]any{"$ref": "#/$defs/binary"},
+					map[string]any{"$ref": "#/$defs/integer"},
+					map[string]any{"$ref": "#/$defs/real"},
+					map[string]any{"$ref": "#/$defs/choice"},
+				},
+			},
+			"value": valueSchema,
+		},
+	}
+	for key, value := range requestSchema {
+		schema[key] = value
+	}
+	output, err = json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		err = fmt.Errorf("failed to export schema: %w", err)
+		return output, err
+	}
+	return output, err
+}
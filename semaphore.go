@@ -0,0 +1,137 @@
+package autocode
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// SetFunctionConcurrency limits how many function options run at once across every evaluation sharing this
+// optimization and its clones; zero removes the limit. Setting FunctionConcurrency directly works the same. A
+// function option that resolves other function options runs them within its own slot, so nesting cannot
+// deadlock on the limit.
+func (self *Optimization) SetFunctionConcurrency(limit int) (err error) {
+	if limit < 0 {
+		err = fmt.Errorf("function concurrency must be non-negative: %d", limit)
+		return err
+	}
+	self.FunctionConcurrency = limit
+	return err
+}
+
+// functionSlots is a semaphore that remembers which goroutines hold a slot, counted per nesting level.
+type functionSlots struct {
+	limit     int
+	semaphore chan struct{}
+	mutex     sync.Mutex
+	holders   map[uint64]int
+}
+
+// functionSlot is the part of a slot held by one invocation. It only returns the semaphore slot when it took one,
+// that is when its goroutine did not hold a slot already.
+type functionSlot struct {
+	slots       *functionSlots
+	goroutineId uint64
+	acquired    bool
+}
+
+// currentGoroutineId reads the id from the first line of the goroutine's stack trace, "goroutine <id> [...".
+func currentGoroutineId() (output uint64) {
+	buffer := make([]byte, 64)
+	buffer = buffer[:runtime.Stack(buffer, false)]
+	fields := bytes.Fields(buffer)
+	if len(fields) < 2 {
+		panic(fmt.Errorf("unexpected stack trace: %q", buffer))
+	}
+	output, parseErr := strconv.ParseUint(string(fields[1]), 10, 64)
+	if parseErr != nil {
+		panic(fmt.Errorf("unexpected stack trace: %q: %w", buffer, parseErr))
+	}
+	return output
+}
+
+// slots returns the semaphore for FunctionConcurrency, rebuilding it when the limit changed, or nil without a
+// limit. Clones share the semaphore as long as they keep the same limit.
+func (self *Optimization) slots() (output *functionSlots) {
+	self.functionSlotsMutex.Lock()
+	defer self.functionSlotsMutex.Unlock()
+	if self.FunctionConcurrency <= 0 {
+		return output
+	}
+	if self.functionSlots == nil || self.functionSlots.limit != self.FunctionConcurrency {
+		self.functionSlots = &functionSlots{
+			limit:     self.FunctionConcurrency,
+			semaphore: make(chan struct{}, self.FunctionConcurrency),
+			holders:   map[uint64]int{},
+		}
+	}
+	output = self.functionSlots
+	return output
+}
+
+func (self *functionSlots) enter(goroutineId uint64) (held bool) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	held = self.holders[goroutineId] > 0
+	self.holders[goroutineId]++
+	return held
+}
+
+func (self *functionSlots) leave(goroutineId uint64) {
+	self.mutex.Lock()
+	defer self.mutex.Unlock()
+	self.holders[goroutineId]--
+	if self.holders[goroutineId] <= 0 {
+		delete(self.holders, goroutineId)
+	}
+}
+
+func (self *Optimization) acquireFunction() (output *functionSlot) {
+	output = &functionSlot{slots: self.slots()}
+	if output.slots == nil {
+		return output
+	}
+	output.goroutineId = currentGoroutineId()
+	held := output.slots.enter(output.goroutineId)
+	if held == false {
+		output.slots.semaphore <- struct{}{}
+		output.acquired = true
+	}
+	return output
+}
+
+func (self *functionSlot) release() {
+	if self.slots == nil {
+		return
+	}
+	self.slots.leave(self.goroutineId)
+	if self.acquired == true {
+		<-self.slots.semaphore
+	}
+}
+
+// handOver moves the slot to a goroutine started by its holder. That goroutine calls adopt on the returned slot
+// first and releases it when it exits; the receiver no longer releases anything, so the slot stays taken for as
+// long as the goroutine runs.
+func (self *functionSlot) handOver() (output *functionSlot) {
+	output = &functionSlot{
+		slots:    self.slots,
+		acquired: self.acquired,
+	}
+	if self.slots != nil {
+		self.slots.leave(self.goroutineId)
+	}
+	self.slots = nil
+	self.acquired = false
+	return output
+}
+
+func (self *functionSlot) adopt() {
+	if self.slots == nil {
+		return
+	}
+	self.goroutineId = currentGoroutineId()
+	self.slots.enter(self.goroutineId)
+}
@@ -0,0 +1,132 @@
+package autocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+func isRecoverableServeError(err error) (output bool) {
+	var addressErr *net.AddrError
+	var parseErr *net.ParseError
+	if errors.As(err, &addressErr) == true || errors.As(err, &parseErr) == true {
+		return false
+	}
+	if errors.Is(err, syscall.EACCES) == true || errors.Is(err, syscall.EINVAL) == true {
+		return false
+	}
+	var operationErr *net.OpError
+	var errno syscall.Errno
+	output = errors.As(err, &operationErr) == true || errors.As(err, &errno) == true
+	return output
+}
+
+const DEFAULT_SHUTDOWN_TIMEOUT = 10 * time.Second
+
+var ErrRunTimeout = errors.New("optimization run timed out")
+
+func (self *Optimization) runDeadline() (output time.Time) {
+	output = self.RunDeadline
+	if self.RunTimeout > 0 {
+		timeoutDeadline := time.Now().Add(self.RunTimeout)
+		if output.IsZero() == true || timeoutDeadline.Before(output) == true {
+			output = timeoutDeadline
+		}
+	}
+	return output
+}
+
+// SuperviseClientServer binds and serves the client server, rebinding with a linear backoff when the bind
+// fails or the listener drops with a recoverable network error. Up to ServeRetryCount consecutive failures
+// are retried; configuration errors such as an invalid address fail immediately. It returns nil once the
+// server is shut down, or ErrRunTimeout once the earlier of RunDeadline and RunTimeout from now passes, after
+// cancelling the run on the backend and shutting the server down.
+func (self *Optimization) SuperviseClientServer() (err error) {
+	deadline := self.runDeadline()
+	if deadline.IsZero() == true {
+		err = self.superviseClientServer(nil)
+		return err
+	}
+	timedOut := &atomic.Bool{}
+	timer := time.AfterFunc(time.Until(deadline), func() {
+		timedOut.Store(true)
+		ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_SHUTDOWN_TIMEOUT)
+		defer cancel()
+		cancelErr := self.Cancel(ctx)
+		if cancelErr != nil {
+			self.Logger.Printf("failed to cancel timed out run: %v", cancelErr)
+			stopErr := self.StopClientServer(ctx)
+			if stopErr != nil {
+				self.Logger.Printf("failed to stop timed out client server: %v", stopErr)
+			}
+		}
+	})
+	defer timer.Stop()
+	err = self.superviseClientServer(timedOut)
+	if timedOut.Load() == true {
+		err = fmt.Errorf("%w after %s", ErrRunTimeout, deadline.Format(time.RFC3339))
+	}
+	return err
+}
+
+func (self *Optimization) isClientServerStopped() (output bool) {
+	self.clientServerMutex.Lock()
+	defer self.clientServerMutex.Unlock()
+	output = self.clientServerStopped
+	return output
+}
+
+func (self *Optimization) superviseClientServer(timedOut *atomic.Bool) (err error) {
+	address := fmt.Sprintf("%s:%d", "0.0.0.0", self.ClientPort)
+	retryBackoff := self.ServeRetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = DEFAULT_RETRY_BACKOFF
+	}
+	failures := 0
+	for {
+		if timedOut != nil && timedOut.Load() == true {
+			return err
+		}
+		server := &fasthttp.Server{
+			Handler:            fasthttpadaptor.NewFastHTTPHandler(self.Handler()),
+			MaxRequestBodySize: int(self.MaxRequestBodySize),
+		}
+		listener, listenErr := self.ListenerOptions.Listen(address)
+		if listenErr == nil {
+			failures = 0
+			self.clientServerMutex.Lock()
+			if self.clientServerStopped == true {
+				self.clientServerMutex.Unlock()
+				err = listener.Close()
+				return err
+			}
+			self.ClientServer = server
+			self.ClientListener = listener
+			self.clientServerMutex.Unlock()
+			err = server.Serve(listener)
+			if err == nil || self.isClientServerStopped() == true {
+				err = nil
+				return err
+			}
+		} else {
+			err = listenErr
+		}
+		if isRecoverableServeError(err) == false {
+			return err
+		}
+		if failures >= self.ServeRetryCount {
+			err = fmt.Errorf("client server gave up after %d retries: %w", failures, err)
+			return err
+		}
+		failures++
+		self.Logger.Printf("client server failed, retrying %d/%d in %s: %v", failures, self.ServeRetryCount, retryBackoff*time.Duration(failures), err)
+		time.Sleep(retryBackoff * time.Duration(failures))
+	}
+}
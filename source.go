@@ -0,0 +1,64 @@
+package autocode
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var ErrSourceUnavailable = errors.New("function source unavailable, set Optimization.SourceRoot or embed the sources with //go:embed into Optimization.SourceFileSystem")
+var ErrFunctionNotFound = errors.New("function not found in source")
+
+// bindSources lets the function options of variables find their source through the SourceRoot and
+// SourceFileSystem of this optimization.
+func (self *Optimization) bindSources(variables map[string]any) {
+	forEachOption(variables, func(option *OptimizationValue) {
+		functionValue, isFunction := option.Data.(*OptimizationFunctionValue)
+		if isFunction == true {
+			functionValue.sourceOwner = self
+		}
+	})
+}
+
+func (self *OptimizationFunctionValue) readSource(fileName string) (output []byte, err error) {
+	sourceRoot := ""
+	var sourceFileSystem fs.FS
+	if self.sourceOwner != nil {
+		sourceRoot = self.sourceOwner.SourceRoot
+		sourceFileSystem = self.sourceOwner.SourceFileSystem
+	}
+	output, err = readSource(fileName, sourceRoot, sourceFileSystem)
+	return output, err
+}
+
+func readSource(fileName string, sourceRoot string, sourceFileSystem fs.FS) (output []byte, err error) {
+	output, readErr := os.ReadFile(fileName)
+	if readErr == nil {
+		return output, err
+	}
+	segments := strings.Split(filepath.ToSlash(fileName), "/")
+	for index := range segments {
+		suffix := path.Join(segments[index:]...)
+		if suffix == "" {
+			continue
+		}
+		if sourceRoot != "" {
+			output, readErr = os.ReadFile(filepath.Join(sourceRoot, filepath.FromSlash(suffix)))
+			if readErr == nil {
+				return output, err
+			}
+		}
+		if sourceFileSystem != nil {
+			output, readErr = fs.ReadFile(sourceFileSystem, suffix)
+			if readErr == nil {
+				return output, err
+			}
+		}
+	}
+	err = fmt.Errorf("%w: %s (source root: %q, source file system set: %t)", ErrSourceUnavailable, fileName, sourceRoot, sourceFileSystem != nil)
+	return output, err
+}
@@ -0,0 +1,42 @@
+package autocode
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFunctionSourceUsesOptimizationFileSystem(t *testing.T) {
+	fileName := "/missing/build/module/pkg/function.go"
+	source := []byte("package pkg\n")
+	tests := []struct {
+		name             string
+		sourceFileSystem fstest.MapFS
+		wantErr          error
+	}{
+		{name: "embedded", sourceFileSystem: fstest.MapFS{"pkg/function.go": {Data: source}}, wantErr: nil},
+		{name: "missing", sourceFileSystem: fstest.MapFS{}, wantErr: ErrSourceUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			choice := NewOptimizationChoice("function", []any{mixedChoiceFunction})
+			optimization := NewOptimization([]any{choice}, nil, "localhost", 0, 0)
+			optimization.SourceFileSystem = test.sourceFileSystem
+			functionValue := choice.Options["function_0"].Data.(*OptimizationFunctionValue)
+			output, err := functionValue.readSource(fileName)
+			if errors.Is(err, test.wantErr) == false || (test.wantErr != nil) != (err != nil) {
+				t.Fatalf("readSource() error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr == nil && string(output) != string(source) {
+				t.Errorf("readSource() = %q, want %q", output, source)
+			}
+			clone := optimization.Clone()
+			clone.SourceFileSystem = fstest.MapFS{}
+			clonedFunctionValue := clone.Variables["function"].(*OptimizationChoice).Options["function_0"].Data.(*OptimizationFunctionValue)
+			_, cloneErr := clonedFunctionValue.readSource(fileName)
+			if errors.Is(cloneErr, ErrSourceUnavailable) == false {
+				t.Errorf("readSource() on clone error = %v, want %v", cloneErr, ErrSourceUnavailable)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package autocode
+
+import (
+	"fmt"
+	"math/big"
+)
+
+func (self *Optimization) DiscreteCardinality() (output *big.Int, fullyDiscrete bool, err error) {
+	output = big.NewInt(1)
+	fullyDiscrete = true
+	for variableId, variable := range self.Variables {
+		switch typedVariable := variable.(type) {
+		case *OptimizationBinary:
+			output.Mul(output, big.NewInt(2))
+		case *OptimizationInteger:
+			if len(typedVariable.Values) > 0 {
+				output.Mul(output, big.NewInt(int64(len(typedVariable.Values))))
+				continue
+			}
+			if typedVariable.Range() < 0 {
+				err = fmt.Errorf("integer variable has inverted bounds: %s", variableId)
+				return output, fullyDiscrete, err
+			}
+			size := new(big.Int).Sub(big.NewInt(typedVariable.UpperBound()), big.NewInt(typedVariable.LowerBound()))
+			output.Mul(output, size.Add(size, big.NewInt(1)))
+		case *OptimizationChoice:
+			if len(typedVariable.Options) == 0 {
+				err = fmt.Errorf("choice variable has no options: %s", variableId)
+				return output, fullyDiscrete, err
+			}
+			output.Mul(output, big.NewInt(int64(len(typedVariable.Options))))
+		case *OptimizationReal:
+			fullyDiscrete = false
+		default:
+			err = fmt.Errorf("unsupported variable type: %T", variable)
+			return output, fullyDiscrete, err
+		}
+	}
+	return output, fullyDiscrete, err
+}
+
+// Dimensions counts each binary, integer, real and choice variable as one optimizable dimension, including the
+// sub-variables of group options. Pinned variables are constants to the backend and count as zero. Conditional
+// variables count as one even while inactive, because the backend samples them whatever the parent value.
+func (self *Optimization) Dimensions() (output int, err error) {
+	for variableId, variable := range self.allVariables() {
+		_, isPinned := self.PinnedValues[variableId]
+		if isPinned == true {
+			continue
+		}
+		switch variable.(type) {
+		case *OptimizationBinary, *OptimizationInteger, *OptimizationReal, *OptimizationChoice:
+			output++
+		default:
+			err = fmt.Errorf("unsupported variable type: %T", variable)
+			return output, err
+		}
+	}
+	return output, err
+}
@@ -0,0 +1,54 @@
+package autocode
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type FunctionStats struct {
+	CallCount       int64
+	TotalDuration   time.Duration
+	AverageDuration time.Duration
+}
+
+type functionStatsCounter struct {
+	callCount        atomic.Int64
+	totalNanoseconds atomic.Int64
+}
+
+func (self *OptimizationFunctionValue) Invoke(optimization *Optimization, arguments ...any) (output any) {
+	if self.isBound() == false {
+		panic(fmt.Errorf("function option is not bound: %s", self.name))
+	}
+	slot := optimization.acquireFunction()
+	if optimization.InstrumentFunctions == false {
+		output = self.call(optimization, arguments, slot)
+		return output
+	}
+	startTime := time.Now()
+	defer func() {
+		self.stats.callCount.Add(1)
+		self.stats.totalNanoseconds.Add(int64(time.Since(startTime)))
+	}()
+	output = self.call(optimization, arguments, slot)
+	return output
+}
+
+func (self *OptimizationFunctionValue) Stats() (output *FunctionStats) {
+	callCount := self.stats.callCount.Load()
+	totalDuration := time.Duration(self.stats.totalNanoseconds.Load())
+	output = &FunctionStats{
+		CallCount:     callCount,
+		TotalDuration: totalDuration,
+	}
+	if callCount > 0 {
+		output.AverageDuration = totalDuration / time.Duration(callCount)
+	}
+	return output
+}
+
+func (self *OptimizationFunctionValue) ResetStats() {
+	self.stats.callCount.Store(0)
+	self.stats.totalNanoseconds.Store(0)
+}
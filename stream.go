@@ -0,0 +1,195 @@
+package autocode
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StreamCodec is implemented by codecs that can encode directly to a writer. Prepare uses it so that function
+// sources are printed into the request body instead of being held in memory as strings.
+type StreamCodec interface {
+	Codec
+	Encode(writer io.Writer, value any) error
+}
+
+type jsonStreamer interface {
+	writeJson(writer *bufio.Writer) error
+}
+
+// functionSource is placed in function value maps in place of the source string. It prints the source only when
+// encoded, and streams it when encoded by a StreamCodec.
+type functionSource struct {
+	function *OptimizationFunctionValue
+}
+
+func (self *functionSource) String() string {
+	return self.function.GetString()
+}
+
+func (self *functionSource) MarshalText() (output []byte, err error) {
+	builder := &strings.Builder{}
+	err = self.function.WriteString(builder)
+	if err != nil {
+		return output, err
+	}
+	output = []byte(builder.String())
+	return output, err
+}
+
+func (self *functionSource) MarshalJSON() (output []byte, err error) {
+	text, textErr := self.MarshalText()
+	if textErr != nil {
+		err = textErr
+		return output, err
+	}
+	output, err = json.Marshal(string(text))
+	return output, err
+}
+
+func (self *functionSource) writeJson(writer *bufio.Writer) (err error) {
+	err = writer.WriteByte('"')
+	if err != nil {
+		return err
+	}
+	err = self.function.WriteString(&jsonStringWriter{writer: writer})
+	if err != nil {
+		return err
+	}
+	err = writer.WriteByte('"')
+	return err
+}
+
+const hexDigits = "0123456789abcdef"
+
+// jsonStringWriter escapes everything written to it as the contents of a JSON string.
+type jsonStringWriter struct {
+	writer *bufio.Writer
+}
+
+func (self *jsonStringWriter) Write(data []byte) (output int, err error) {
+	for _, character := range data {
+		switch {
+		case character == '"' || character == '\\':
+			err = self.writer.WriteByte('\\')
+			if err == nil {
+				err = self.writer.WriteByte(character)
+			}
+		case character == '\n':
+			_, err = self.writer.WriteString(`\n`)
+		case character == '\t':
+			_, err = self.writer.WriteString(`\t`)
+		case character == '\r':
+			_, err = self.writer.WriteString(`\r`)
+		case character < 0x20:
+			_, err = self.writer.WriteString(`\u00`)
+			if err == nil {
+				_, err = self.writer.Write([]byte{hexDigits[character>>4], hexDigits[character&0xF]})
+			}
+		default:
+			err = self.writer.WriteByte(character)
+		}
+		if err != nil {
+			return output, err
+		}
+		output++
+	}
+	return output, err
+}
+
+func writeJsonStream(writer *bufio.Writer, value any) (err error) {
+	switch typedValue := value.(type) {
+	case jsonStreamer:
+		err = typedValue.writeJson(writer)
+	case map[string]any:
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		err = writer.WriteByte('{')
+		for index, key := range keys {
+			if err != nil {
+				return err
+			}
+			if index > 0 {
+				err = writer.WriteByte(',')
+				if err != nil {
+					return err
+				}
+			}
+			err = writeJsonStream(writer, key)
+			if err != nil {
+				return err
+			}
+			err = writer.WriteByte(':')
+			if err != nil {
+				return err
+			}
+			err = writeJsonStream(writer, typedValue[key])
+		}
+		if err != nil {
+			return err
+		}
+		err = writer.WriteByte('}')
+	case []any:
+		err = writer.WriteByte('[')
+		for index, item := range typedValue {
+			if err != nil {
+				return err
+			}
+			if index > 0 {
+				err = writer.WriteByte(',')
+				if err != nil {
+					return err
+				}
+			}
+			err = writeJsonStream(writer, item)
+		}
+		if err != nil {
+			return err
+		}
+		err = writer.WriteByte(']')
+	default:
+		data, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			err = marshalErr
+			return err
+		}
+		_, err = writer.Write(data)
+	}
+	return err
+}
+
+// Encode writes value as JSON. Nested map[string]any and []any values are walked so that function sources are
+// streamed; other values are encoded with encoding/json.
+func (self *JsonCodec) Encode(writer io.Writer, value any) (err error) {
+	bufferedWriter := bufio.NewWriter(writer)
+	err = writeJsonStream(bufferedWriter, value)
+	if err != nil {
+		err = fmt.Errorf("failed to encode stream: %w", err)
+		return err
+	}
+	err = bufferedWriter.Flush()
+	return err
+}
+
+// streamBody starts encoding body into a pipe. wait returns the encoding error once the reader is drained or
+// closed.
+func streamBody(codec StreamCodec, body any) (reader io.ReadCloser, wait func() error) {
+	pipeReader, pipeWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		encodeErr := codec.Encode(pipeWriter, body)
+		pipeWriter.CloseWithError(encodeErr)
+		done <- encodeErr
+	}()
+	reader = pipeReader
+	wait = func() error {
+		return <-done
+	}
+	return reader, wait
+}
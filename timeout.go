@@ -0,0 +1,114 @@
+package autocode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ContextFunctionValue is a function option that receives a context, cancelled when the option Timeout expires.
+type ContextFunctionValue = func(ctx context.Context, optimization *Optimization, arguments ...any) any
+
+var ErrFunctionTimeout = errors.New("function value timed out")
+
+func newFunctionValue(function any) (output *OptimizationFunctionValue) {
+	switch typedFunction := function.(type) {
+	case *OptimizationFunctionValue:
+		output = typedFunction
+	case FunctionValue:
+		output = &OptimizationFunctionValue{Function: typedFunction}
+	case ContextFunctionValue:
+		output = &OptimizationFunctionValue{ContextFunction: typedFunction}
+	default:
+		panic(fmt.Errorf("unsupported function value type: %T", function))
+	}
+	return output
+}
+
+var ErrTimeoutWithoutContext = errors.New("function value timeout needs a ContextFunctionValue")
+
+// NewFunctionValueWithTimeout wraps a ContextFunctionValue so that each invocation fails once timeout elapses. The
+// function sees its context cancelled and should return promptly; until it does, it keeps its FunctionConcurrency
+// slot. A plain FunctionValue cannot be interrupted and is rejected.
+func NewFunctionValueWithTimeout(function ContextFunctionValue, timeout time.Duration) (output *OptimizationFunctionValue) {
+	output = newFunctionValue(function)
+	output.Timeout = timeout
+	return output
+}
+
+// checkTimeout rejects a Timeout on a function without a ContextFunction, e.g. set on the field directly.
+func (self *OptimizationFunctionValue) checkTimeout() (err error) {
+	if self.Timeout > 0 && self.Function != nil && self.ContextFunction == nil {
+		err = fmt.Errorf("%w: %s", ErrTimeoutWithoutContext, self.GetName())
+	}
+	return err
+}
+
+func (self *OptimizationFunctionValue) isBound() (output bool) {
+	output = self.Function != nil || self.ContextFunction != nil
+	return output
+}
+
+func (self *OptimizationFunctionValue) target() (output any) {
+	if self.ContextFunction != nil {
+		output = self.ContextFunction
+		return output
+	}
+	output = self.Function
+	return output
+}
+
+func (self *OptimizationFunctionValue) callWithContext(ctx context.Context, optimization *Optimization, arguments []any) (output any) {
+	if self.ContextFunction != nil {
+		output = self.ContextFunction(ctx, optimization, arguments...)
+		return output
+	}
+	output = self.Function(optimization, arguments...)
+	return output
+}
+
+type functionResult struct {
+	output    any
+	recovered any
+}
+
+// call runs the function and releases slot. With a Timeout it runs in a goroutine that takes the slot over
+// until the function returns, even after the timeout. A timeout or a panic inside the goroutine is raised as a
+// panic in the caller, like any other GetValue failure.
+func (self *OptimizationFunctionValue) call(optimization *Optimization, arguments []any, slot *functionSlot) (output any) {
+	if self.Timeout <= 0 {
+		defer slot.release()
+		output = self.callWithContext(context.Background(), optimization, arguments)
+		return output
+	}
+	timeoutErr := self.checkTimeout()
+	if timeoutErr != nil {
+		slot.release()
+		panic(timeoutErr)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), self.Timeout)
+	defer cancel()
+	done := make(chan functionResult, 1)
+	goroutineSlot := slot.handOver()
+	go func() {
+		goroutineSlot.adopt()
+		defer goroutineSlot.release()
+		result := functionResult{}
+		defer func() {
+			result.recovered = optimization.reportPanic(recover())
+			done <- result
+		}()
+		result.output = self.callWithContext(ctx, optimization, arguments)
+	}()
+	select {
+	case result := <-done:
+		if result.recovered != nil {
+			panic(result.recovered)
+		}
+		output = result.output
+	case <-ctx.Done():
+		panic(fmt.Errorf("%w: %s after %s", ErrFunctionTimeout, self.GetName(), self.Timeout))
+	}
+	return output
+}
@@ -0,0 +1,92 @@
+package autocode
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+const TIMING_WALL = "wall"
+const TIMING_CPU = "cpu"
+
+// timingEpoch anchors wall time readings so that they use the monotonic clock.
+var timingEpoch = time.Now()
+
+// TimingObjective fills the objective at Index with the seconds spent in Evaluate, overwriting whatever the
+// application put there. CPU time is read for the whole process, so concurrent work elsewhere in the process
+// is counted too.
+type TimingObjective struct {
+	Index int
+	Clock string
+}
+
+func (self *Optimization) SetTimingObjective(index int, clock string) (err error) {
+	if clock != TIMING_WALL && clock != TIMING_CPU {
+		err = fmt.Errorf("unsupported timing clock: %s", clock)
+		return err
+	}
+	if index < 0 {
+		err = fmt.Errorf("timing objective index must be non-negative: %d", index)
+		return err
+	}
+	if self.Counts != nil && index >= self.Counts.NumObjectives {
+		err = fmt.Errorf("timing objective index %d is out of the %d declared objectives", index, self.Counts.NumObjectives)
+		return err
+	}
+	if clock == TIMING_CPU {
+		_, err = processCpuTime()
+		if err != nil {
+			return err
+		}
+	}
+	self.TimingObjective = &TimingObjective{
+		Index: index,
+		Clock: clock,
+	}
+	return err
+}
+
+func (self *Optimization) ClearTimingObjective() {
+	self.TimingObjective = nil
+}
+
+func (self *TimingObjective) now() (output time.Duration, err error) {
+	if self.Clock == TIMING_CPU {
+		output, err = processCpuTime()
+		return output, err
+	}
+	output = time.Since(timingEpoch)
+	return output, err
+}
+
+// evaluateTimed runs the evaluation and writes its duration into the timing objective slot of a copy of the
+// response. Infeasible responses are left untouched since their objectives are replaced by the penalty.
+func (self *Optimization) evaluateTimed() (output *OptimizationEvaluateRunResponse, err error) {
+	if self.TimingObjective == nil {
+		output = self.evaluatePenalized()
+		return output, err
+	}
+	startTime, startErr := self.TimingObjective.now()
+	if startErr != nil {
+		err = startErr
+		return output, err
+	}
+	output = self.evaluatePenalized()
+	endTime, endErr := self.TimingObjective.now()
+	if endErr != nil {
+		err = endErr
+		return output, err
+	}
+	if output == nil || output.Infeasible == true {
+		return output, err
+	}
+	if self.TimingObjective.Index >= len(output.Objectives) {
+		err = fmt.Errorf("timing objective %d has no slot, evaluation returned %d", self.TimingObjective.Index, len(output.Objectives))
+		return output, err
+	}
+	timedOutput := *output
+	timedOutput.Objectives = slices.Clone(output.Objectives)
+	timedOutput.Objectives[self.TimingObjective.Index] = (endTime - startTime).Seconds()
+	output = &timedOutput
+	return output, err
+}
@@ -0,0 +1,30 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+func (self *Optimization) SetEqualityTolerances(tolerances []float64) (err error) {
+	for index, tolerance := range tolerances {
+		if tolerance < 0 {
+			err = fmt.Errorf("equality constraint tolerance must be non-negative: index %d: %v", index, tolerance)
+			return err
+		}
+	}
+	self.EqualityTolerances = append([]float64{}, tolerances...)
+	return err
+}
+
+// EqualityToInequalities turns each equality constraint h(x) == 0 with tolerance t into the pair of inequality
+// constraints h(x) - t <= 0 and -h(x) - t <= 0. Constraints without a tolerance use zero.
+func EqualityToInequalities(equalityConstraints []float64, tolerances []float64) (output []float64) {
+	output = make([]float64, 0, 2*len(equalityConstraints))
+	for index, equalityConstraint := range equalityConstraints {
+		tolerance := 0.0
+		if index < len(tolerances) {
+			tolerance = tolerances[index]
+		}
+		output = append(output, equalityConstraint-tolerance, -equalityConstraint-tolerance)
+	}
+	return output
+}
@@ -0,0 +1,101 @@
+package autocode
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const OPTIMIZE_TAG = "optimize"
+
+func isIntegerKind(kind reflect.Kind) (output bool) {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		output = true
+	}
+	return output
+}
+
+func isFloatKind(kind reflect.Kind) (output bool) {
+	output = kind == reflect.Float32 || kind == reflect.Float64
+	return output
+}
+
+func convertValue(value reflect.Value, targetType reflect.Type) (output reflect.Value, err error) {
+	if value.Type().AssignableTo(targetType) {
+		output = value
+		return output, err
+	}
+	sourceKind := value.Kind()
+	targetKind := targetType.Kind()
+	compatible := (isIntegerKind(sourceKind) && isIntegerKind(targetKind)) ||
+		(isFloatKind(sourceKind) && isFloatKind(targetKind)) ||
+		(sourceKind == reflect.Bool && targetKind == reflect.Bool)
+	if compatible == false || value.CanConvert(targetType) == false {
+		err = fmt.Errorf("type mismatch: cannot assign %s to %s", value.Type(), targetType)
+		return output, err
+	}
+	zero := reflect.Zero(targetType)
+	if isIntegerKind(targetKind) == true && zero.OverflowInt(value.Int()) == true {
+		err = fmt.Errorf("value %d overflows %s", value.Int(), targetType)
+		return output, err
+	}
+	if isFloatKind(targetKind) == true && zero.OverflowFloat(value.Float()) == true {
+		err = fmt.Errorf("value %v overflows %s", value.Float(), targetType)
+		return output, err
+	}
+	output = value.Convert(targetType)
+	return output, err
+}
+
+func (self *Optimization) getValueSafe(variableId string) (output any, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("failed to get value of %s: %v", variableId, recovered)
+		}
+	}()
+	output = self.GetValue(variableId)
+	return output, err
+}
+
+func (self *Optimization) Unmarshal(into any) (err error) {
+	err = self.unmarshal("", into)
+	return err
+}
+
+func (self *Optimization) unmarshal(prefix string, into any) (err error) {
+	intoValue := reflect.ValueOf(into)
+	if intoValue.Kind() != reflect.Pointer || intoValue.IsNil() || intoValue.Elem().Kind() != reflect.Struct {
+		err = fmt.Errorf("expected a non-nil pointer to struct: %T", into)
+		return err
+	}
+	structValue := intoValue.Elem()
+	structType := structValue.Type()
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+		_, tagExists := field.Tag.Lookup(OPTIMIZE_TAG)
+		if tagExists == false {
+			continue
+		}
+		if field.IsExported() == false {
+			err = fmt.Errorf("tagged field must be exported: %s", field.Name)
+			return err
+		}
+		value, valueErr := self.getValueSafe(prefix + field.Name)
+		if valueErr != nil {
+			err = valueErr
+			return err
+		}
+		if value == nil {
+			err = fmt.Errorf("value of field %s is nil", field.Name)
+			return err
+		}
+		convertedValue, convertErr := convertValue(reflect.ValueOf(value), field.Type)
+		if convertErr != nil {
+			err = fmt.Errorf("field %s: %w", field.Name, convertErr)
+			return err
+		}
+		structValue.Field(index).Set(convertedValue)
+	}
+	return err
+}
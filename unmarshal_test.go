@@ -0,0 +1,47 @@
+package autocode
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestConvertValueOverflow(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      any
+		targetType reflect.Type
+		valid      bool
+	}{
+		{name: "int8 fits", value: int64(127), targetType: reflect.TypeOf(int8(0)), valid: true},
+		{name: "int8 overflows", value: int64(300), targetType: reflect.TypeOf(int8(0)), valid: false},
+		{name: "int32 underflows", value: int64(math.MinInt32 - 1), targetType: reflect.TypeOf(int32(0)), valid: false},
+		{name: "float32 fits", value: 0.5, targetType: reflect.TypeOf(float32(0)), valid: true},
+		{name: "float32 overflows", value: 1e300, targetType: reflect.TypeOf(float32(0)), valid: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := convertValue(reflect.ValueOf(test.value), test.targetType)
+			if (err == nil) != test.valid {
+				t.Fatalf("convertValue() error = %v, want valid %t", err, test.valid)
+			}
+			if test.valid == true && output.Type() != test.targetType {
+				t.Errorf("convertValue() type = %s, want %s", output.Type(), test.targetType)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRejectsOverflow(t *testing.T) {
+	optimization := NewOptimization([]any{NewOptimizationInteger("Depth", 0, 1000)}, nil, "localhost", 0, 0)
+	optimization.VariableValues = map[string]*OptimizationValue{
+		"Depth": {Type: VALUE_INTEGER, Data: int64(300)},
+	}
+	into := &struct {
+		Depth int8 `optimize:"integer,0,1000"`
+	}{}
+	err := optimization.Unmarshal(into)
+	if err == nil {
+		t.Fatalf("Unmarshal() error = nil, Depth = %d, want an overflow error", into.Depth)
+	}
+}
@@ -0,0 +1,55 @@
+package autocode
+
+import (
+	"sort"
+	"sync"
+)
+
+type variableUsage struct {
+	accessed    map[string]bool
+	evaluations int
+	mutex       sync.Mutex
+}
+
+func (self *Optimization) recordAccess(variableId string) {
+	self.usage.mutex.Lock()
+	defer self.usage.mutex.Unlock()
+	if self.usage.accessed == nil {
+		self.usage.accessed = map[string]bool{}
+	}
+	self.usage.accessed[variableId] = true
+}
+
+// recordEvaluation counts a finished evaluation and logs the unused variables once, after UnusedWarningAfter
+// evaluations, if it is positive.
+func (self *Optimization) recordEvaluation() {
+	self.usage.mutex.Lock()
+	self.usage.evaluations++
+	evaluations := self.usage.evaluations
+	self.usage.mutex.Unlock()
+	if self.UnusedWarningAfter <= 0 || evaluations != self.UnusedWarningAfter {
+		return
+	}
+	unusedVariableIds := self.UnusedVariables()
+	if len(unusedVariableIds) > 0 {
+		self.Logger.Printf("variables not read by the first %d evaluations: %v", evaluations, unusedVariableIds)
+	}
+}
+
+// UnusedVariables returns the sorted ids of declared variables that no evaluation has read through GetValue. It
+// returns nil before the first evaluation. Variables that are only read under some conditions may show up until
+// such a candidate is evaluated.
+func (self *Optimization) UnusedVariables() (output []string) {
+	self.usage.mutex.Lock()
+	defer self.usage.mutex.Unlock()
+	if self.usage.evaluations == 0 {
+		return output
+	}
+	for variableId := range self.Variables {
+		if self.usage.accessed[variableId] == false {
+			output = append(output, variableId)
+		}
+	}
+	sort.Strings(output)
+	return output
+}
@@ -0,0 +1,136 @@
+package autocode
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+type VariableError struct {
+	VariableId string
+	Err        error
+}
+
+func (self *VariableError) Error() string {
+	return fmt.Sprintf("invalid variable %s: %v", self.VariableId, self.Err)
+}
+
+func (self *VariableError) Unwrap() error {
+	return self.Err
+}
+
+func (self *OptimizationChoice) validate(failFast bool) (errs []error) {
+	if len(self.Options) == 0 {
+		errs = append(errs, fmt.Errorf("choice %s has no options", self.Id))
+		if failFast == true {
+			return errs
+		}
+	}
+	for _, optionId := range self.SortedOptionIds() {
+		option := self.Options[optionId]
+		if option.Type != VALUE_FUNCTION {
+			continue
+		}
+		functionValue := option.Data.(*OptimizationFunctionValue)
+		timeoutErr := functionValue.checkTimeout()
+		if timeoutErr != nil {
+			errs = append(errs, fmt.Errorf("invalid function option %s of choice %s: %w", optionId, self.Id, timeoutErr))
+			if failFast == true {
+				return errs
+			}
+		}
+		_, _, parseErr := functionValue.TryParse()
+		if parseErr != nil {
+			errs = append(errs, fmt.Errorf("invalid function option %s of choice %s: %w", optionId, self.Id, parseErr))
+			if failFast == true {
+				return errs
+			}
+		}
+	}
+	return errs
+}
+
+func (self *OptimizationChoice) Validate() (err error) {
+	errs := self.validate(true)
+	if len(errs) > 0 {
+		err = errs[0]
+	}
+	return err
+}
+
+// warnChoiceOptions logs choices above ChoiceOptionsSoftLimit. Prepare calls it once per run, right before the
+// options are sent.
+func (self *Optimization) warnChoiceOptions() {
+	if self.ChoiceOptionsSoftLimit <= 0 {
+		return
+	}
+	for variableId, variable := range self.allVariables() {
+		choice, isChoice := variable.(*OptimizationChoice)
+		if isChoice == true && len(choice.Options) > self.ChoiceOptionsSoftLimit {
+			self.Logger.Printf("choice %s has %d options, above the soft limit of %d, expect a large prepare payload", variableId, len(choice.Options), self.ChoiceOptionsSoftLimit)
+		}
+	}
+}
+
+func (self *Optimization) validateVariable(variableId string, variable any, failFast bool) (errs []error) {
+	idErr := ValidateId(variableId)
+	if idErr != nil {
+		errs = append(errs, idErr)
+		if failFast == true {
+			return errs
+		}
+	}
+	switch typedVariable := variable.(type) {
+	case *OptimizationInteger:
+		if typedVariable.Range() < 0 {
+			errs = append(errs, fmt.Errorf("inverted bounds: %v", typedVariable.Bounds))
+		}
+	case *OptimizationReal:
+		if typedVariable.Range() < 0 {
+			errs = append(errs, fmt.Errorf("inverted bounds: %v", typedVariable.Bounds))
+		}
+	case *OptimizationChoice:
+		if self.ChoiceOptionsHardLimit > 0 && len(typedVariable.Options) > self.ChoiceOptionsHardLimit {
+			errs = append(errs, fmt.Errorf("choice has %d options, above the hard limit of %d", len(typedVariable.Options), self.ChoiceOptionsHardLimit))
+			if failFast == true {
+				return errs
+			}
+		}
+		errs = append(errs, typedVariable.validate(failFast)...)
+	}
+	return errs
+}
+
+func (self *Optimization) validate(failFast bool) (errs []error) {
+	variables := self.allVariables()
+	variableIds := []string{}
+	for variableId := range variables {
+		variableIds = append(variableIds, variableId)
+	}
+	sort.Strings(variableIds)
+	for _, variableId := range variableIds {
+		for _, variableErr := range self.validateVariable(variableId, variables[variableId], failFast) {
+			errs = append(errs, &VariableError{
+				VariableId: variableId,
+				Err:        variableErr,
+			})
+			if failFast == true {
+				return errs
+			}
+		}
+	}
+	return errs
+}
+
+func (self *Optimization) Validate() (err error) {
+	errs := self.validate(true)
+	if len(errs) > 0 {
+		err = errs[0]
+	}
+	return err
+}
+
+func (self *Optimization) ValidateAll() (err error) {
+	err = errors.Join(self.validate(false)...)
+	return err
+}
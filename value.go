@@ -0,0 +1,107 @@
+package autocode
+
+import (
+	"fmt"
+)
+
+// Go returns the native value held by an OptimizationValue: bool, int64, float64, []byte, the FunctionValue or
+// OptimizationApplication of an option, the nested *OptimizationChoice or *OptimizationGroup, or the custom
+// option data.
+func (self *OptimizationValue) Go() (output any, err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("invalid %s data %v: %v", self.Type, self.Data, recovered)
+		}
+	}()
+	switch self.Type {
+	case VALUE_BOOLEAN:
+		output = toBool(self.Data)
+	case VALUE_INTEGER:
+		output = toInt64(self.Data)
+	case VALUE_FLOAT:
+		output = toFloat64(self.Data)
+	case VALUE_BYTES:
+		output = decodeBytes(self.Data)
+	case VALUE_FUNCTION:
+		output = self.Data.(*OptimizationFunctionValue).target()
+	case VALUE_APPLICATION:
+		output = self.Data.(*OptimizationApplicationValue).Application
+	case VARIABLE_CHOICE:
+		output = self.Data.(*OptimizationChoice)
+	case VALUE_GROUP:
+		output = self.Data.(*OptimizationGroup)
+	case VALUE_CUSTOM:
+		output = self.Data
+	default:
+		err = fmt.Errorf("unsupported value type: %s", self.Type)
+	}
+	return output, err
+}
+
+// NewOptimizationValueFromGo wraps a native value for a variable after checking it against the variable domain
+// with validateValue, the check applied to candidates. Binaries take a bool, integers an int64 and reals a
+// float64. Choices take an option id, or a scalar equal to the data of an integer, float or boolean option, and
+// return that option.
+func NewOptimizationValueFromGo(variable any, value any) (output *OptimizationValue, err error) {
+	switch typedVariable := variable.(type) {
+	case *OptimizationBinary:
+		booleanValue, isBoolean := value.(bool)
+		if isBoolean == false {
+			err = fmt.Errorf("value of binary variable %s must be bool: %T", typedVariable.Id, value)
+			return output, err
+		}
+		output = &OptimizationValue{Id: typedVariable.Id, Type: VALUE_BOOLEAN, Data: booleanValue}
+	case *OptimizationInteger:
+		integerValue, isInteger := value.(int64)
+		if isInteger == false {
+			err = fmt.Errorf("value of integer variable %s must be int64: %T", typedVariable.Id, value)
+			return output, err
+		}
+		output = &OptimizationValue{Id: typedVariable.Id, Type: VALUE_INTEGER, Data: integerValue}
+	case *OptimizationReal:
+		realValue, isReal := value.(float64)
+		if isReal == false {
+			err = fmt.Errorf("value of real variable %s must be float64: %T", typedVariable.Id, value)
+			return output, err
+		}
+		output = &OptimizationValue{Id: typedVariable.Id, Type: VALUE_FLOAT, Data: realValue}
+	case *OptimizationChoice:
+		output = choiceOptionOf(typedVariable, value)
+		if output == nil {
+			err = fmt.Errorf("value of choice variable %s is not an option: %v", typedVariable.Id, value)
+			return output, err
+		}
+	default:
+		err = fmt.Errorf("unsupported variable type: %T", variable)
+		return output, err
+	}
+	validateErr := validateValue(variable, output)
+	if validateErr != nil {
+		err = fmt.Errorf("value of %s variable %s: %w", getType(variable), variableBase(variable).Id, validateErr)
+		output = nil
+	}
+	return output, err
+}
+
+// choiceOptionOf returns the option with the given id, or the integer, float or boolean option holding value.
+func choiceOptionOf(choice *OptimizationChoice, value any) (output *OptimizationValue) {
+	optionId, isOptionId := value.(string)
+	if isOptionId == true {
+		option, optionExists := choice.Options[optionId]
+		if optionExists == true {
+			output = option
+			return output
+		}
+	}
+	for _, option := range choice.Options {
+		switch option.Type {
+		case VALUE_INTEGER, VALUE_FLOAT, VALUE_BOOLEAN:
+			if option.Data == value {
+				output = option
+				return output
+			}
+		}
+	}
+	return output
+}